@@ -23,14 +23,20 @@ func New(ctx context.Context, cfg config.Config) (Conditioner, error) { //nolint
 		return newMetaAny(ctx, cfg)
 	case "none", "meta_none":
 		return newMetaNone(ctx, cfg)
+	case "meta_threshold":
+		return newMetaThreshold(ctx, cfg)
 	// Format inspectors.
 	case "format_mime":
 		return newFormatMIME(ctx, cfg)
 	case "format_json":
 		return newFormatJSON(ctx, cfg)
+	case "format_json_schema":
+		return newFormatJSONSchema(ctx, cfg)
 	// Network inspectors.
 	case "network_ip_global_unicast":
 		return newNetworkIPGlobalUnicast(ctx, cfg)
+	case "network_ip_in_cidr":
+		return newNetworkIPInCIDR(ctx, cfg)
 	case "network_ip_link_local_multicast":
 		return newNetworkIPLinkLocalMulticast(ctx, cfg)
 	case "network_ip_link_local_unicast":
@@ -68,22 +74,51 @@ func New(ctx context.Context, cfg config.Config) (Conditioner, error) { //nolint
 		return newNumberLengthGreaterThan(ctx, cfg)
 	case "number_length_equal_to":
 		return newNumberLengthEqualTo(ctx, cfg)
+	// Object inspectors.
+	case "object_jq":
+		return newObjectJQ(ctx, cfg)
+	// Sigma inspectors.
+	case "sigma":
+		return newSigma(ctx, cfg)
 	// String inspectors.
 	case "string_contains":
 		return newStringContains(ctx, cfg)
+	case "string_contains_any":
+		return newStringContainsAny(ctx, cfg)
 	case "string_ends_with":
 		return newStringEndsWith(ctx, cfg)
+	case "string_entropy_greater_than":
+		return newStringEntropyGreaterThan(ctx, cfg)
 	case "string_equal_to":
 		return newStringEqualTo(ctx, cfg)
 	case "string_greater_than":
 		return newStringGreaterThan(ctx, cfg)
+	case "string_in_bloom_filter":
+		return newStringInBloomFilter(ctx, cfg)
+	case "string_in_set":
+		return newStringInSet(ctx, cfg)
+	case "string_is_base64":
+		return newStringIsBase64(ctx, cfg)
+	case "string_is_hex":
+		return newStringIsHex(ctx, cfg)
 	case "string_less_than":
 		return newStringLessThan(ctx, cfg)
 	case "string_starts_with":
 		return newStringStartsWith(ctx, cfg)
 	case "string_match":
 		return newStringMatch(ctx, cfg)
+	case "string_similar_to":
+		return newStringSimilarTo(ctx, cfg)
+	// Time inspectors.
+	case "time_after":
+		return newTimeAfter(ctx, cfg)
+	case "time_before":
+		return newTimeBefore(ctx, cfg)
+	case "time_within":
+		return newTimeWithin(ctx, cfg)
 	// Utility inspectors.
+	case "utility_hash_sample":
+		return newUtilityHashSample(ctx, cfg)
 	case "utility_random":
 		return newUtilityRandom(ctx, cfg)
 	default:
@@ -0,0 +1,101 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/jsonschema"
+)
+
+type formatJSONSchemaConfig struct {
+	// Schema is a JSON Schema (draft 2020-12) that is used to validate data. The
+	// format keyword is asserted (e.g., "format": "date-time" only matches timestamps).
+	Schema map[string]interface{} `json:"schema"`
+	// File contains the location of a file that contains a JSON Schema. This can
+	// be either a path on local disk, an HTTP(S) URL, or an AWS S3 URL.
+	File string `json:"file"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *formatJSONSchemaConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *formatJSONSchemaConfig) Validate() error {
+	if c.Schema == nil && c.File == "" {
+		return fmt.Errorf("schema: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newFormatJSONSchema(ctx context.Context, cfg config.Config) (*formatJSONSchema, error) {
+	conf := formatJSONSchemaConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	v, err := jsonschema.New(ctx, conf.Schema, conf.File)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := formatJSONSchema{
+		conf:      conf,
+		validator: v,
+	}
+
+	return &insp, nil
+}
+
+type formatJSONSchema struct {
+	conf formatJSONSchemaConfig
+
+	validator *jsonschema.Validator
+}
+
+// Condition returns true if the data is valid according to the schema. Data
+// that is not JSON is not valid.
+func (insp *formatJSONSchema) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	b := msg.Data()
+	if insp.conf.Object.SourceKey != "" {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		// The value is encoded so that strings are validated as JSON strings.
+		v, err := json.Marshal(value.Value())
+		if err != nil {
+			return false, err
+		}
+
+		b = v
+	}
+
+	errs, err := insp.validator.Validate(b)
+	if err != nil {
+		return false, nil
+	}
+
+	return len(errs) == 0, nil
+}
+
+func (insp *formatJSONSchema) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
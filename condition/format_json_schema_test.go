@@ -0,0 +1,223 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &formatJSONSchema{}
+
+var formatJSONSchemaTestSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"id", "tags"},
+	"properties": map[string]interface{}{
+		"id": map[string]interface{}{
+			"type": "integer",
+		},
+		"tags": map[string]interface{}{
+			"type":     "array",
+			"items":    map[string]interface{}{"type": "string"},
+			"minItems": 1,
+		},
+	},
+}
+
+var formatJSONSchemaTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+			},
+		},
+		[]byte(`{"id":1,"tags":["a"]}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"schema": map[string]interface{}{
+					"type":   "string",
+					"format": "email",
+				},
+			},
+		},
+		[]byte(`{"a":"b@example.com"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+			},
+		},
+		[]byte(`{"id":"1","tags":[]}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"schema": map[string]interface{}{
+					"type":   "string",
+					"format": "email",
+				},
+			},
+		},
+		[]byte(`{"a":"b"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+			},
+		},
+		[]byte(`foo`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "b",
+				},
+				"schema": formatJSONSchemaTestSchema,
+			},
+		},
+		[]byte(`{"a":{"id":1,"tags":["a"]}}`),
+		false,
+	},
+}
+
+func TestFormatJSONSchema(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range formatJSONSchemaTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newFormatJSONSchema(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestFormatJSONSchemaFile(t *testing.T) {
+	ctx := context.TODO()
+
+	f := filepath.Join(t.TempDir(), "schema.json")
+	schema := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"a":{"const":"b"}}}`
+	if err := os.WriteFile(f, []byte(schema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insp, err := newFormatJSONSchema(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"file": f,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for data, expected := range map[string]bool{
+		`{"a":"b"}`: true,
+		`{"a":"c"}`: false,
+	} {
+		check, err := insp.Condition(ctx, message.New().SetData([]byte(data)))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if expected != check {
+			t.Errorf("expected %v, got %v, %v", expected, check, data)
+		}
+	}
+}
+
+func benchmarkFormatJSONSchemaByte(b *testing.B, insp *formatJSONSchema, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkFormatJSONSchemaByte(b *testing.B) {
+	for _, test := range formatJSONSchemaTests {
+		insp, err := newFormatJSONSchema(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkFormatJSONSchemaByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestFormatJSONSchema(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"id":1,"tags":["a"]}`),
+		[]byte(`{"id":"1","tags":[]}`),
+		[]byte(`[1,2,3]`),
+		[]byte(`foo`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newFormatJSONSchema(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"schema": formatJSONSchemaTestSchema,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/kv"
+)
+
+// metaThresholdMaxAttempts limits the number of times a counter is updated
+// when other writers modify the counter at the same time.
+const metaThresholdMaxAttempts = 10
+
+var errMetaThresholdConflict = fmt.Errorf("too many conflicting counter updates")
+
+type metaThresholdConfig struct {
+	// Threshold is the number of times that a key must be seen within the window
+	// before the condition returns true.
+	Threshold int `json:"threshold"`
+	// Window is the duration of time that keys are counted in (e.g., "5m").
+	Window string `json:"window"`
+	// WindowType determines how the window is applied. Must be one of:
+	//
+	// - fixed: counts reset at the start of every window.
+	//
+	// - sliding: counts are approximated over the previous window of time by
+	// weighting the count from the previous fixed window.
+	//
+	// This is optional and defaults to "fixed".
+	WindowType string `json:"window_type"`
+	// OncePerWindow determines whether the condition only returns true the first
+	// time that the threshold is crossed in a window.
+	//
+	// This is optional and defaults to false.
+	OncePerWindow bool `json:"once_per_window"`
+	// Prefix is prepended to counter keys in the KV store. This should be used if
+	// multiple conditions share the same KV store.
+	//
+	// This is optional and defaults to no prefix.
+	Prefix string `json:"prefix"`
+	// KVStore is the KV store that counters are stored in. The KV store must
+	// support conditional writes (aws_dynamodb, bbolt, memory, or redis) so that
+	// counters are updated atomically.
+	KVStore config.Config `json:"kv_store"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *metaThresholdConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *metaThresholdConfig) Validate() error {
+	if c.Threshold < 0 {
+		return fmt.Errorf("threshold %d: %v", c.Threshold, iconfig.ErrInvalidOption)
+	}
+
+	if c.Threshold == 0 {
+		return fmt.Errorf("threshold: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Window == "" {
+		return fmt.Errorf("window: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.KVStore.Type == "" {
+		return fmt.Errorf("kv_store: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Object.SourceKey == "" {
+		return fmt.Errorf("object_source_key: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	switch c.WindowType {
+	case "", "fixed", "sliding":
+	default:
+		return fmt.Errorf("window_type %s: %v", c.WindowType, iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newMetaThreshold(_ context.Context, cfg config.Config) (*metaThreshold, error) {
+	conf := metaThresholdConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	window, err := time.ParseDuration(conf.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	// TTLs in KV stores have a resolution of one second.
+	if window < time.Second {
+		return nil, fmt.Errorf("window %s: %v", conf.Window, iconfig.ErrInvalidOption)
+	}
+
+	kvStore, err := kv.Get(conf.KVStore)
+	if err != nil {
+		return nil, err
+	}
+
+	// Counters are lost when concurrent updates overwrite each other.
+	if !kv.IsConditional(kvStore) {
+		return nil, fmt.Errorf("kv_store %s: %v", conf.KVStore.Type, iconfig.ErrInvalidOption)
+	}
+
+	insp := metaThreshold{
+		conf:    conf,
+		window:  window,
+		kvStore: kvStore,
+		now:     time.Now,
+	}
+
+	return &insp, nil
+}
+
+type metaThreshold struct {
+	conf metaThresholdConfig
+
+	window  time.Duration
+	kvStore kv.Storer
+	now     func() time.Time
+}
+
+// Condition increments the counter for the key and returns true if the count
+// is greater than or equal to the threshold.
+func (insp *metaThreshold) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	value := msg.GetValue(insp.conf.Object.SourceKey)
+	if !value.Exists() {
+		return false, nil
+	}
+
+	if !insp.kvStore.IsEnabled() {
+		if err := insp.kvStore.Setup(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	now := insp.now()
+	idx := now.UnixNano() / insp.window.Nanoseconds()
+	key := insp.conf.Prefix + value.String()
+
+	// Counters are kept for an extra window when the window is sliding so that
+	// they can be used to weight the count of the next window.
+	end := time.Unix(0, (idx+1)*insp.window.Nanoseconds())
+	ttl := end
+	if insp.conf.WindowType == "sliding" {
+		ttl = end.Add(insp.window)
+	}
+
+	cur, err := insp.increment(ctx, fmt.Sprintf("%s:%d", key, idx), ttl)
+	if err != nil {
+		return false, err
+	}
+
+	count := float64(cur)
+	if insp.conf.WindowType == "sliding" {
+		prev, err := insp.kvStore.Get(ctx, fmt.Sprintf("%s:%d", key, idx-1))
+		if err != nil {
+			return false, err
+		}
+
+		// The previous window is weighted by how much of it overlaps with the
+		// sliding window that ends now.
+		start := time.Unix(0, idx*insp.window.Nanoseconds())
+		weight := 1 - float64(now.Sub(start))/float64(insp.window)
+		count += metaThresholdCount(prev) * weight
+	}
+
+	if count < float64(insp.conf.Threshold) {
+		return false, nil
+	}
+
+	if !insp.conf.OncePerWindow {
+		return true, nil
+	}
+
+	// A sliding window has no fixed end, so the marker expires one window after
+	// the threshold is crossed.
+	if insp.conf.WindowType == "sliding" {
+		return insp.mark(ctx, key+":once", now.Add(insp.window))
+	}
+
+	return insp.mark(ctx, fmt.Sprintf("%s:%d:once", key, idx), end)
+}
+
+func (insp *metaThreshold) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
+
+// increment adds one to the counter and returns the new count.
+func (insp *metaThreshold) increment(ctx context.Context, key string, ttl time.Time) (int64, error) {
+	exp := metaThresholdTTL(ttl)
+
+	// The KV store is validated when the condition is created.
+	s := insp.kvStore.(kv.ConditionalStorer)
+	for i := 0; i < metaThresholdMaxAttempts; i++ {
+		v, err := insp.kvStore.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+
+		cur := int64(metaThresholdCount(v)) + 1
+		if v == nil {
+			err = s.SetIfNotExistsWithTTL(ctx, key, cur, exp)
+		} else {
+			err = s.CompareAndSetWithTTL(ctx, key, v, cur, exp)
+		}
+
+		if errors.Is(err, kv.ErrConditionFailed) {
+			continue
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		return cur, nil
+	}
+
+	return 0, errMetaThresholdConflict
+}
+
+// mark returns true if the key was not previously marked in the KV store.
+func (insp *metaThreshold) mark(ctx context.Context, key string, ttl time.Time) (bool, error) {
+	s := insp.kvStore.(kv.ConditionalStorer)
+	err := s.SetIfNotExistsWithTTL(ctx, key, true, metaThresholdTTL(ttl))
+	if errors.Is(err, kv.ErrConditionFailed) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// metaThresholdTTL converts a time to a TTL. Partial seconds are rounded up so
+// that items never expire before the time.
+func metaThresholdTTL(t time.Time) int64 {
+	return int64(math.Ceil(float64(t.UnixNano()) / float64(time.Second)))
+}
+
+// metaThresholdCount converts a counter retrieved from a KV store to a number.
+// Depending on the store, numbers are returned as different types.
+func metaThresholdCount(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+
+	return 0
+}
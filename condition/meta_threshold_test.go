@@ -0,0 +1,332 @@
+package condition
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+var _ Conditioner = &metaThreshold{}
+
+// Each test uses a unique prefix because KV stores with the same configuration
+// are shared.
+var metaThresholdTests = []struct {
+	name     string
+	cfg      config.Config
+	test     [][]byte
+	offset   []time.Duration
+	expected []bool
+}{
+	{
+		"fixed",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "user",
+				},
+				"threshold": 3,
+				"window":    "1m",
+				"prefix":    "fixed:",
+				"kv_store": map[string]interface{}{
+					"type": "memory",
+				},
+			},
+		},
+		[][]byte{
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"b"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+		},
+		[]time.Duration{0, time.Second, time.Second, 2 * time.Second, 3 * time.Second, time.Minute},
+		[]bool{false, false, false, true, true, false},
+	},
+	{
+		"fixed once_per_window",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "user",
+				},
+				"threshold":       2,
+				"window":          "1m",
+				"once_per_window": true,
+				"prefix":          "fixed_once:",
+				"kv_store": map[string]interface{}{
+					"type": "memory",
+				},
+			},
+		},
+		[][]byte{
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+		},
+		[]time.Duration{0, time.Second, 2 * time.Second, time.Minute, time.Minute + time.Second},
+		[]bool{false, true, false, false, true},
+	},
+	{
+		"sliding",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "user",
+				},
+				"threshold":   3,
+				"window":      "1m",
+				"window_type": "sliding",
+				"prefix":      "sliding:",
+				"kv_store": map[string]interface{}{
+					"type": "memory",
+				},
+			},
+		},
+		[][]byte{
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			[]byte(`{"user":"a"}`),
+			// The previous window is weighted by 0.75.
+			[]byte(`{"user":"a"}`),
+			// The previous window is weighted by 0.25.
+			[]byte(`{"user":"a"}`),
+		},
+		[]time.Duration{45 * time.Second, 50 * time.Second, 55 * time.Second, 75 * time.Second, 105 * time.Second},
+		[]bool{false, false, true, true, false},
+	},
+	{
+		"missing key",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "user",
+				},
+				"threshold": 1,
+				"window":    "1m",
+				"prefix":    "missing:",
+				"kv_store": map[string]interface{}{
+					"type": "memory",
+				},
+			},
+		},
+		[][]byte{
+			[]byte(`{"a":"b"}`),
+			[]byte(`{"user":"a"}`),
+		},
+		[]time.Duration{0, 0},
+		[]bool{false, true},
+	},
+}
+
+func TestMetaThreshold(t *testing.T) {
+	ctx := context.TODO()
+
+	// The start time is aligned to a window so that tests are deterministic.
+	start := time.Now().Truncate(time.Hour).Add(time.Hour)
+
+	for _, test := range metaThresholdTests {
+		t.Run(test.name, func(t *testing.T) {
+			insp, err := newMetaThreshold(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, data := range test.test {
+				insp.now = func() time.Time { return start.Add(test.offset[i]) }
+
+				check, err := insp.Condition(ctx, message.New().SetData(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if test.expected[i] != check {
+					t.Errorf("expected %v, got %v, %d: %s", test.expected[i], check, i, string(data))
+				}
+			}
+		})
+	}
+}
+
+func TestMetaThresholdValidate(t *testing.T) {
+	for _, settings := range []map[string]interface{}{
+		{"threshold": 1, "window": "1m", "object": map[string]interface{}{"source_key": "a"}},
+		{"threshold": 1, "window": "1m", "kv_store": map[string]interface{}{"type": "memory"}},
+		{"window": "1m", "kv_store": map[string]interface{}{"type": "memory"}, "object": map[string]interface{}{"source_key": "a"}},
+		{"threshold": -1, "window": "1m", "kv_store": map[string]interface{}{"type": "memory"}, "object": map[string]interface{}{"source_key": "a"}},
+		{"threshold": 1, "window": "1ms", "kv_store": map[string]interface{}{"type": "memory"}, "object": map[string]interface{}{"source_key": "a"}},
+		{"threshold": 1, "window": "1m", "window_type": "foo", "kv_store": map[string]interface{}{"type": "memory"}, "object": map[string]interface{}{"source_key": "a"}},
+		// KV stores that do not support conditional writes lose counts.
+		{"threshold": 1, "window": "1m", "kv_store": map[string]interface{}{"type": "text_file", "settings": map[string]interface{}{"file": "a"}}, "object": map[string]interface{}{"source_key": "a"}},
+	} {
+		if _, err := newMetaThreshold(context.TODO(), config.Config{Settings: settings}); err == nil {
+			t.Errorf("expected error, got nil: %v", settings)
+		}
+	}
+}
+
+func TestMetaThresholdValidateThreshold(t *testing.T) {
+	tests := []struct {
+		threshold int
+		expected  error
+	}{
+		{0, iconfig.ErrMissingRequiredOption},
+		{-1, iconfig.ErrInvalidOption},
+	}
+
+	for _, test := range tests {
+		conf := metaThresholdConfig{Threshold: test.threshold}
+
+		err := conf.Validate()
+		if err == nil || !strings.Contains(err.Error(), test.expected.Error()) {
+			t.Errorf("threshold %d: expected %v, got %v", test.threshold, test.expected, err)
+		}
+	}
+}
+
+func TestMetaThresholdConcurrent(t *testing.T) {
+	ctx := context.TODO()
+
+	const goroutines, increments = 8, 100
+
+	tests := []struct {
+		name          string
+		threshold     int
+		oncePerWindow bool
+		expected      int64
+	}{
+		// Every increment after the threshold returns true.
+		{"threshold", goroutines * increments / 2, false, goroutines*increments/2 + 1},
+		{"once_per_window", goroutines * increments / 2, true, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			insp, err := newMetaThreshold(ctx, config.Config{
+				Settings: map[string]interface{}{
+					"object": map[string]interface{}{
+						"source_key": "user",
+					},
+					"threshold":       test.threshold,
+					"window":          "1h",
+					"once_per_window": test.oncePerWindow,
+					"prefix":          "concurrent:" + test.name + ":",
+					"kv_store": map[string]interface{}{
+						"type": "memory",
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every increment is in the same window.
+			start := time.Now()
+			insp.now = func() time.Time { return start }
+
+			var (
+				wg    sync.WaitGroup
+				count atomic.Int64
+			)
+
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					for j := 0; j < increments; j++ {
+						check, err := insp.Condition(ctx, message.New().SetData([]byte(`{"user":"a"}`)))
+						if err != nil {
+							t.Error(err)
+							return
+						}
+
+						if check {
+							count.Add(1)
+						}
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if c := count.Load(); c != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, c)
+			}
+		})
+	}
+}
+
+func benchmarkMetaThresholdByte(b *testing.B, insp *metaThreshold, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkMetaThresholdByte(b *testing.B) {
+	for _, test := range metaThresholdTests {
+		insp, err := newMetaThreshold(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test[0])
+				benchmarkMetaThresholdByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestMetaThreshold(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"user":"a"}`),
+		[]byte(`{"user":["a","b"]}`),
+		[]byte(`{"user":""}`),
+		[]byte(`{"a":"b"}`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newMetaThreshold(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "user",
+			},
+			"threshold": 2,
+			"window":    "1m",
+			"prefix":    "fuzz:",
+			"kv_store": map[string]interface{}{
+				"type": "memory",
+			},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package condition
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/cidr"
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+type networkIPInCIDRConfig struct {
+	// CIDRs is a list of CIDR ranges (e.g., 10.0.0.0/8, 2001:db8::/32). IP
+	// addresses are treated as single host ranges (/32 or /128).
+	CIDRs []string `json:"cidrs"`
+	// File contains the location of a file that contains CIDR ranges, one per
+	// line. This can be either a path on local disk, an HTTP(S) URL, or an AWS
+	// S3 URL. Empty lines and lines that start with "#" are ignored.
+	File string `json:"file"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *networkIPInCIDRConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *networkIPInCIDRConfig) Validate() error {
+	if len(c.CIDRs) == 0 && c.File == "" {
+		return fmt.Errorf("cidrs: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newNetworkIPInCIDR(ctx context.Context, cfg config.Config) (*networkIPInCIDR, error) {
+	conf := networkIPInCIDRConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	insp := networkIPInCIDR{
+		conf: conf,
+	}
+
+	for _, c := range conf.CIDRs {
+		if err := insp.tree.InsertString(c, nil); err != nil {
+			return nil, fmt.Errorf("%s: %v", c, err)
+		}
+	}
+
+	if conf.File != "" {
+		if err := insp.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return &insp, nil
+}
+
+type networkIPInCIDR struct {
+	conf networkIPInCIDRConfig
+
+	// tree is read-only after the condition is created, so it is
+	// safe for concurrent access.
+	tree cidr.Tree
+}
+
+// Condition returns true if the IP address is in any of the CIDR ranges.
+// Values that are not IP addresses return false.
+func (insp *networkIPInCIDR) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var str string
+	if insp.conf.Object.SourceKey == "" {
+		str = string(msg.Data())
+	} else {
+		str = msg.GetValue(insp.conf.Object.SourceKey).String()
+	}
+
+	addr, err := netip.ParseAddr(str)
+	if err != nil {
+		return false, nil
+	}
+
+	_, ok := insp.tree.Lookup(addr)
+	return ok, nil
+}
+
+func (insp *networkIPInCIDR) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
+
+// load reads CIDR ranges from the file into the tree.
+func (insp *networkIPInCIDR) load(ctx context.Context) error {
+	path, err := file.Get(ctx, insp.conf.File)
+	defer os.Remove(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := insp.tree.InsertString(line, nil); err != nil {
+			return fmt.Errorf("%s: %v", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,190 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &networkIPInCIDR{}
+
+var networkIPInCIDRTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"cidrs": []string{"10.0.0.0/8", "192.168.1.1"},
+			},
+		},
+		[]byte("10.1.2.3"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ip_address",
+				},
+				"cidrs": []string{"10.0.0.0/8", "2001:db8::/32"},
+			},
+		},
+		[]byte(`{"ip_address":"2001:db8::1"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"cidrs": []string{"10.0.0.0/8"},
+			},
+		},
+		[]byte("::ffff:10.1.2.3"),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"cidrs": []string{"10.0.0.0/8", "192.168.1.1"},
+			},
+		},
+		[]byte("192.168.1.2"),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"cidrs": []string{"10.0.0.0/8"},
+			},
+		},
+		[]byte("foo"),
+		false,
+	},
+}
+
+func TestNetworkIPInCIDR(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range networkIPInCIDRTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newNetworkIPInCIDR(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestNetworkIPInCIDRFile(t *testing.T) {
+	ctx := context.TODO()
+
+	f := filepath.Join(t.TempDir(), "cidrs.txt")
+	if err := os.WriteFile(f, []byte("# office\n172.16.0.0/12\n\n2001:db8::/32\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insp, err := newNetworkIPInCIDR(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"file": f,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ip, expected := range map[string]bool{
+		"172.20.1.1":  true,
+		"2001:db8::1": true,
+		"172.32.0.1":  false,
+		"2001:db9::1": false,
+	} {
+		check, err := insp.Condition(ctx, message.New().SetData([]byte(ip)))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if expected != check {
+			t.Errorf("expected %v, got %v, %v", expected, check, ip)
+		}
+	}
+}
+
+func benchmarkNetworkIPInCIDRByte(b *testing.B, insp *networkIPInCIDR, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkNetworkIPInCIDRByte(b *testing.B) {
+	for _, test := range networkIPInCIDRTests {
+		insp, err := newNetworkIPInCIDR(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkNetworkIPInCIDRByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestNetworkIPInCIDR(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"ip_address":"10.1.2.3"}`),
+		[]byte(`{"ip_address":"2001:db8::1"}`),
+		[]byte(`{"ip_address":"invalid"}`),
+		[]byte(`10.1.2.3`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newNetworkIPInCIDR(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "ip_address",
+			},
+			"cidrs": []string{"10.0.0.0/8", "2001:db8::/32"},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type objectJQConfig struct {
+	// Filter is the jq filter applied to data. The condition is true if the
+	// first output of the filter is not false or null.
+	Filter string `json:"filter"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *objectJQConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *objectJQConfig) Validate() error {
+	if c.Filter == "" {
+		return fmt.Errorf("filter: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newObjectJQ(_ context.Context, cfg config.Config) (*objectJQ, error) {
+	conf := objectJQConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	q, err := gojq.Parse(conf.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := objectJQ{
+		conf: conf,
+		code: code,
+	}
+
+	return &insp, nil
+}
+
+type objectJQ struct {
+	conf objectJQConfig
+
+	// code is safe for concurrent use.
+	code *gojq.Code
+}
+
+// Condition returns true if the first output of the jq filter is truthy (not
+// false or null). If the filter generates no output, then the condition
+// returns false.
+func (insp *objectJQ) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var i interface{}
+	if insp.conf.Object.SourceKey == "" {
+		if err := json.Unmarshal(msg.Data(), &i); err != nil {
+			return false, err
+		}
+	} else {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		i = value.Value()
+	}
+
+	iter := insp.code.RunWithContext(ctx, i)
+	v, ok := iter.Next()
+	if !ok {
+		return false, nil
+	}
+
+	if err, ok := v.(error); ok {
+		return false, err
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	default:
+		return true, nil
+	}
+}
+
+func (insp *objectJQ) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
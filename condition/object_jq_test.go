@@ -0,0 +1,178 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &objectJQ{}
+
+var objectJQTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"filter": `any(.resources[]; .type == "bucket" and .account == "123")`,
+			},
+		},
+		[]byte(`{"resources":[{"type":"role","account":"123"},{"type":"bucket","account":"123"}]}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"filter": `.a`,
+			},
+		},
+		[]byte(`{"a":"b"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"filter": `length > 1`,
+			},
+		},
+		[]byte(`{"a":[1,2,3]}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"filter": `any(.resources[]; .type == "bucket" and .account == "123")`,
+			},
+		},
+		[]byte(`{"resources":[{"type":"role","account":"123"},{"type":"bucket","account":"456"}]}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"filter": `.b`,
+			},
+		},
+		[]byte(`{"a":"b"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"filter": `.[] | select(. > 5)`,
+			},
+		},
+		[]byte(`[1,2,3]`),
+		false,
+	},
+}
+
+func TestObjectJQ(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range objectJQTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newObjectJQ(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestObjectJQError(t *testing.T) {
+	ctx := context.TODO()
+
+	insp, err := newObjectJQ(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"filter": `.a + 1`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := insp.Condition(ctx, message.New().SetData([]byte(`{"a":"b"}`))); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func benchmarkObjectJQByte(b *testing.B, insp *objectJQ, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkObjectJQByte(b *testing.B) {
+	for _, test := range objectJQTests {
+		insp, err := newObjectJQ(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkObjectJQByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestObjectJQ(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"b"}`),
+		[]byte(`{"a":[1,2,3]}`),
+		[]byte(`{"resources":[{"type":"bucket"}]}`),
+		[]byte(`[1,2,3]`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newObjectJQ(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"filter": `.a`,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
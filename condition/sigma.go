@@ -0,0 +1,102 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/sigma"
+)
+
+type sigmaConfig struct {
+	// Rules is a list of Sigma rules in YAML format. Multiple rules in a single
+	// value are separated by "---".
+	Rules []string `json:"rules"`
+	// Files is a list of locations of files that contain Sigma rules. These can
+	// be either a path on local disk, an HTTP(S) URL, or an AWS S3 URL.
+	Files []string `json:"files"`
+	// Fields maps Sigma field names to message keys (e.g., CommandLine to
+	// process.command_line).
+	//
+	// This is optional and defaults to using Sigma field names as message keys.
+	Fields map[string]string `json:"fields"`
+}
+
+func (c *sigmaConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *sigmaConfig) Validate() error {
+	if len(c.Rules) == 0 && len(c.Files) == 0 {
+		return fmt.Errorf("rules: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newSigma(ctx context.Context, cfg config.Config) (*sigmaCondition, error) {
+	conf := sigmaConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	rules, err := sigma.Load(ctx, conf.Rules, conf.Files, conf.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := sigmaCondition{
+		conf: conf,
+	}
+
+	insp.cnds = make([]Conditioner, len(rules))
+	for i, r := range rules {
+		cnd, err := New(ctx, r.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", r.ID, err)
+		}
+
+		insp.cnds[i] = cnd
+	}
+
+	return &insp, nil
+}
+
+type sigmaCondition struct {
+	conf sigmaConfig
+
+	cnds []Conditioner
+}
+
+// Condition returns true if the message matches any of the rules.
+func (insp *sigmaCondition) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	for _, cnd := range insp.cnds {
+		ok, err := cnd.Condition(ctx, msg)
+		if err != nil {
+			return false, err
+		}
+
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (insp *sigmaCondition) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
@@ -0,0 +1,244 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &sigmaCondition{}
+
+var sigmaTestRule = `
+title: Suspicious Encoded PowerShell
+id: 5b3a0d1e-0000-4000-8000-000000000001
+logsource:
+  product: windows
+  category: process_creation
+detection:
+  selection_img:
+    Image|endswith:
+      - '\powershell.exe'
+      - '\pwsh.exe'
+  selection_cli:
+    CommandLine|contains|all:
+      - ' -enc'
+      - 'bypass'
+  filter_admin:
+    User: 'ADMIN*'
+  condition: all of selection_* and not filter_admin
+`
+
+var sigmaTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{sigmaTestRule},
+			},
+		},
+		[]byte(`{"Image":"C:\\Windows\\System32\\PowerShell.exe","CommandLine":"powershell -ExecutionPolicy Bypass -enc SQBFAFgA","User":"alice"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{sigmaTestRule},
+				"fields": map[string]string{
+					"Image":       "process.executable",
+					"CommandLine": "process.command_line",
+					"User":        "user.name",
+				},
+			},
+		},
+		[]byte(`{"process":{"executable":"C:\\pwsh.exe","command_line":"pwsh -enc AAAA -ep bypass"},"user":{"name":"bob"}}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{`
+detection:
+  keywords:
+    - 'mimikatz'
+    - 'sekurlsa::*'
+  selection:
+    src_ip|cidr: 10.0.0.0/8
+    tags: admin
+  condition: keywords or selection
+`},
+			},
+		},
+		[]byte(`{"src_ip":"10.1.2.3","tags":["user","admin"]}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{`
+detection:
+  selection:
+    CommandLine|base64|contains: 'http://example.com'
+    EventID: 4688
+  condition: selection
+`},
+			},
+		},
+		[]byte(`{"CommandLine":"echo aHR0cDovL2V4YW1wbGUuY29t | base64 -d","EventID":4688}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{sigmaTestRule},
+			},
+		},
+		[]byte(`{"Image":"C:\\Windows\\System32\\powershell.exe","CommandLine":"powershell -ExecutionPolicy Bypass -enc SQBFAFgA","User":"Administrator"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{sigmaTestRule},
+			},
+		},
+		[]byte(`{"Image":"C:\\Windows\\System32\\powershell.exe","CommandLine":"powershell -enc SQBFAFgA","User":"alice"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": []string{`
+detection:
+  selection:
+    CommandLine|re: '^cmd\.exe /c .*whoami$'
+    ParentImage: null
+  condition: selection
+`},
+			},
+		},
+		[]byte(`{"CommandLine":"cmd.exe /c whoami","ParentImage":"explorer.exe"}`),
+		false,
+	},
+}
+
+func TestSigma(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range sigmaTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newSigma(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestSigmaFile(t *testing.T) {
+	ctx := context.TODO()
+
+	f := filepath.Join(t.TempDir(), "rules.yml")
+	rules := sigmaTestRule + "\n---\ntitle: Whoami\ndetection:\n  selection:\n    CommandLine|endswith: whoami\n  condition: selection\n"
+	if err := os.WriteFile(f, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insp, err := newSigma(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"files": []string{f},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := insp.Condition(ctx, message.New().SetData([]byte(`{"CommandLine":"cmd.exe /c WHOAMI"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !check {
+		t.Errorf("expected true, got %v", check)
+	}
+}
+
+func benchmarkSigmaByte(b *testing.B, insp *sigmaCondition, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkSigmaByte(b *testing.B) {
+	for _, test := range sigmaTests {
+		insp, err := newSigma(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkSigmaByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestSigma(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"Image":"C:\\pwsh.exe","CommandLine":"pwsh -enc AAAA -ep bypass","User":"alice"}`),
+		[]byte(`{"Image":["a","b"],"CommandLine":1}`),
+		[]byte(`{"User":null}`),
+		[]byte(`powershell.exe`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newSigma(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"rules": []string{sigmaTestRule},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package condition
 
 import (
+	"math"
+	"unicode/utf8"
+
 	iconfig "github.com/brexhq/substation/v2/internal/config"
 )
 
@@ -14,3 +17,151 @@ type stringConfig struct {
 func (c *stringConfig) Decode(in interface{}) error {
 	return iconfig.Decode(in, c)
 }
+
+type stringEntropyConfig struct {
+	// Value is the Shannon entropy, in bits per character, used for comparison
+	// during inspection. Random strings (e.g., DGA domains) are usually greater
+	// than 3.5 and English words are usually less than 3.
+	Value float64 `json:"value"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *stringEntropyConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+type stringEncodingConfig struct {
+	// MinLength is the minimum number of characters that a string must have to
+	// be considered encoded. Short strings are often valid encodings by chance
+	// (e.g., "cafe" is valid hex).
+	//
+	// This is optional and defaults to 1.
+	MinLength int `json:"min_length"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *stringEncodingConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+// stringEntropy returns the Shannon entropy of a string in bits per character.
+func stringEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(utf8.RuneCountInString(s))
+
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / n
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
+
+// stringLevenshtein returns the minimum number of single character edits
+// (insertions, deletions, or substitutions) that change a into b.
+func stringLevenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	// Only the previous row of the matrix is needed.
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur := min(row[j]+1, row[j-1]+1, prev+cost)
+			prev, row[j] = row[j], cur
+		}
+	}
+
+	return row[len(b)]
+}
+
+// stringJaroWinkler returns the Jaro-Winkler similarity of two strings, where
+// 1 is an exact match and 0 is no similarity.
+func stringJaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	aMatch := make([]bool, len(a))
+	bMatch := make([]bool, len(b))
+
+	var matches int
+	for i := range a {
+		lo, hi := max(0, i-window), min(len(b), i+window+1)
+		for j := lo; j < hi; j++ {
+			if bMatch[j] || a[i] != b[j] {
+				continue
+			}
+
+			aMatch[i], bMatch[j] = true, true
+			matches++
+
+			break
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	var transpositions, k int
+	for i := range a {
+		if !aMatch[i] {
+			continue
+		}
+
+		for !bMatch[k] {
+			k++
+		}
+
+		if a[i] != b[k] {
+			transpositions++
+		}
+
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Strings that share a prefix (up to 4 characters) are more similar.
+	var prefix int
+	for prefix < min(4, len(a), len(b)) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,332 @@
+package condition
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+type stringContainsAnyConfig struct {
+	// Values is a list of substrings that are searched for during inspection.
+	Values []string `json:"values"`
+	// File contains the location of a file that contains substrings, one per
+	// line. This can be either a path on local disk, an HTTP(S) URL, or an AWS
+	// S3 URL. Empty lines are ignored.
+	File string `json:"file"`
+	// CaseInsensitive determines whether the search ignores case.
+	//
+	// This is optional and defaults to false.
+	CaseInsensitive bool `json:"case_insensitive"`
+	// MetadataKey is the key in the message's metadata that the matched substrings
+	// are written to (e.g., "matches" is written to "meta matches"). If this is
+	// used, then the entire string is searched to find all matches.
+	//
+	// This is optional and defaults to not writing matches.
+	MetadataKey string `json:"metadata_key"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *stringContainsAnyConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *stringContainsAnyConfig) Validate() error {
+	if len(c.Values) == 0 && c.File == "" {
+		return fmt.Errorf("values: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newStringContainsAny(ctx context.Context, cfg config.Config) (*stringContainsAny, error) {
+	conf := stringContainsAnyConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	values := conf.Values
+	if conf.File != "" {
+		v, err := stringContainsAnyLoad(ctx, conf.File)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(append([]string(nil), values...), v...)
+	}
+
+	insp := stringContainsAny{
+		conf: conf,
+	}
+
+	patterns := make([][]byte, 0, len(values))
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		b := []byte(v)
+		if conf.CaseInsensitive {
+			b = bytes.ToLower(b)
+		}
+
+		patterns = append(patterns, b)
+		insp.values = append(insp.values, v)
+	}
+
+	insp.ac = newAhoCorasick(patterns)
+
+	return &insp, nil
+}
+
+type stringContainsAny struct {
+	conf stringContainsAnyConfig
+
+	// values are the original substrings, indexed by pattern.
+	values []string
+	// ac is read-only after the condition is created, so it is safe for
+	// concurrent access.
+	ac *ahoCorasick
+}
+
+// Condition returns true if the string contains any of the substrings.
+func (insp *stringContainsAny) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var b []byte
+	if insp.conf.Object.SourceKey == "" {
+		b = msg.Data()
+	} else {
+		b = msg.GetValue(insp.conf.Object.SourceKey).Bytes()
+	}
+
+	if insp.conf.CaseInsensitive {
+		b = bytes.ToLower(b)
+	}
+
+	if insp.conf.MetadataKey == "" {
+		return insp.ac.contains(b), nil
+	}
+
+	idx := insp.ac.matches(b)
+	if len(idx) == 0 {
+		return false, nil
+	}
+
+	terms := make([]string, len(idx))
+	for i, p := range idx {
+		terms[i] = insp.values[p]
+	}
+
+	if err := msg.SetValue("meta "+insp.conf.MetadataKey, terms); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (insp *stringContainsAny) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
+
+// stringContainsAnyLoad reads substrings from a file.
+func stringContainsAnyLoad(ctx context.Context, location string) ([]string, error) {
+	path, err := file.Get(ctx, location)
+	defer os.Remove(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		values = append(values, line)
+	}
+
+	return values, scanner.Err()
+}
+
+// ahoCorasick is an Aho-Corasick automaton that finds all patterns in a string
+// in a single pass. Searches are O(n + m), where n is the length of the string
+// and m is the number of matches.
+type ahoCorasick struct {
+	nodes []acNode
+	// root contains the transitions from the root node. Every byte has a
+	// transition, so searches never follow failure links from the root.
+	root [256]int32
+}
+
+type acNode struct {
+	// edges are sorted by byte.
+	edges []acEdge
+	// fail is the node for the longest proper suffix that is also a prefix of a pattern.
+	fail int32
+	// dict is the nearest node along the failure links that ends a pattern, or -1.
+	dict int32
+	// pattern is the index of the pattern that ends at this node, or -1.
+	pattern int32
+}
+
+type acEdge struct {
+	b    byte
+	node int32
+}
+
+func (n *acNode) next(b byte) int32 {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].b >= b })
+	if i < len(n.edges) && n.edges[i].b == b {
+		return n.edges[i].node
+	}
+
+	return -1
+}
+
+func newAhoCorasick(patterns [][]byte) *ahoCorasick {
+	ac := &ahoCorasick{
+		nodes: []acNode{{fail: 0, dict: -1, pattern: -1}},
+	}
+
+	// Build the trie.
+	for i, p := range patterns {
+		n := int32(0)
+		for _, b := range p {
+			next := ac.nodes[n].next(b)
+			if next == -1 {
+				next = int32(len(ac.nodes))
+				ac.nodes = append(ac.nodes, acNode{dict: -1, pattern: -1})
+
+				edges := ac.nodes[n].edges
+				j := sort.Search(len(edges), func(j int) bool { return edges[j].b >= b })
+				edges = append(edges, acEdge{})
+				copy(edges[j+1:], edges[j:])
+				edges[j] = acEdge{b: b, node: next}
+				ac.nodes[n].edges = edges
+			}
+
+			n = next
+		}
+
+		// If a pattern is duplicated, then the first one is used.
+		if ac.nodes[n].pattern == -1 {
+			ac.nodes[n].pattern = int32(i)
+		}
+	}
+
+	// Build the failure and dictionary links in breadth-first order.
+	var queue []int32
+	for _, e := range ac.nodes[0].edges {
+		queue = append(queue, e.node)
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, e := range ac.nodes[n].edges {
+			f := ac.nodes[n].fail
+			for f != 0 && ac.nodes[f].next(e.b) == -1 {
+				f = ac.nodes[f].fail
+			}
+
+			if next := ac.nodes[f].next(e.b); next != -1 {
+				f = next
+			}
+
+			ac.nodes[e.node].fail = f
+			if ac.nodes[f].pattern != -1 {
+				ac.nodes[e.node].dict = f
+			} else {
+				ac.nodes[e.node].dict = ac.nodes[f].dict
+			}
+
+			queue = append(queue, e.node)
+		}
+	}
+
+	for b := 0; b < 256; b++ {
+		if next := ac.nodes[0].next(byte(b)); next != -1 {
+			ac.root[b] = next
+		}
+	}
+
+	return ac
+}
+
+func (ac *ahoCorasick) step(n int32, b byte) int32 {
+	for n != 0 {
+		if next := ac.nodes[n].next(b); next != -1 {
+			return next
+		}
+
+		n = ac.nodes[n].fail
+	}
+
+	return ac.root[b]
+}
+
+// contains returns true if any pattern is in the string.
+func (ac *ahoCorasick) contains(s []byte) bool {
+	var n int32
+	for _, b := range s {
+		n = ac.step(n, b)
+		if ac.nodes[n].pattern != -1 || ac.nodes[n].dict != -1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns the index of every pattern that is in the string, in the
+// order that they are first found.
+func (ac *ahoCorasick) matches(s []byte) []int {
+	var out []int
+	seen := make(map[int32]struct{})
+
+	var n int32
+	for _, b := range s {
+		n = ac.step(n, b)
+		for m := n; m != -1; m = ac.nodes[m].dict {
+			p := ac.nodes[m].pattern
+			if p == -1 {
+				continue
+			}
+
+			if _, ok := seen[p]; !ok {
+				seen[p] = struct{}{}
+				out = append(out, int(p))
+			}
+		}
+	}
+
+	return out
+}
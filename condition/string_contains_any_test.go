@@ -0,0 +1,235 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringContainsAny{}
+
+var stringContainsAnyTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"values": []string{"mimikatz", "sekurlsa", "-enc"},
+			},
+		},
+		[]byte("powershell.exe -nop -enc SQBFAFgA"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":           []string{"mimikatz", "sekurlsa"},
+				"case_insensitive": true,
+			},
+		},
+		[]byte(`{"a":"Invoke-MimiKatz"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"values": []string{"mimikatz", "sekurlsa"},
+			},
+		},
+		[]byte("Invoke-MimiKatz"),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values": []string{"mimikatz", "sekurlsa"},
+			},
+		},
+		[]byte(`{"a":"notepad.exe"}`),
+		false,
+	},
+}
+
+func TestStringContainsAny(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringContainsAnyTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringContainsAny(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestStringContainsAnyMetadata(t *testing.T) {
+	ctx := context.TODO()
+
+	f := filepath.Join(t.TempDir(), "terms.txt")
+	if err := os.WriteFile(f, []byte("she\nhers\n\nhis\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insp, err := newStringContainsAny(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"values":           []string{"he"},
+			"file":             f,
+			"case_insensitive": true,
+			"metadata_key":     "matches",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := message.New().SetData([]byte("USHERS"))
+	check, err := insp.Condition(ctx, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !check {
+		t.Errorf("expected true, got %v", check)
+	}
+
+	var matches []string
+	for _, v := range msg.GetValue("meta matches").Array() {
+		matches = append(matches, v.String())
+	}
+
+	expected := []string{"she", "he", "hers"}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("expected %v, got %v", expected, matches)
+	}
+}
+
+// TestStringContainsAnyAutomaton compares the automaton to strings.Contains.
+func TestStringContainsAnyAutomaton(t *testing.T) {
+	patterns := []string{"a", "ab", "bab", "bc", "bca", "c", "caa", "abcab"}
+	inputs := []string{"", "abccab", "bbbb", "xcaax", "babab", "zzz", "abcabc"}
+
+	var b [][]byte
+	for _, p := range patterns {
+		b = append(b, []byte(p))
+	}
+
+	ac := newAhoCorasick(b)
+	for _, in := range inputs {
+		var expected []string
+		for _, p := range patterns {
+			if strings.Contains(in, p) {
+				expected = append(expected, p)
+			}
+		}
+
+		var got []string
+		for _, i := range ac.matches([]byte(in)) {
+			got = append(got, patterns[i])
+		}
+
+		if len(got) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", in, expected, got)
+		}
+
+		for _, g := range got {
+			if !strings.Contains(in, g) {
+				t.Errorf("%s: unexpected match %s", in, g)
+			}
+		}
+
+		if ac.contains([]byte(in)) != (len(expected) > 0) {
+			t.Errorf("%s: expected contains %v", in, len(expected) > 0)
+		}
+	}
+}
+
+func benchmarkStringContainsAnyByte(b *testing.B, insp *stringContainsAny, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringContainsAnyByte(b *testing.B) {
+	for _, test := range stringContainsAnyTests {
+		insp, err := newStringContainsAny(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringContainsAnyByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringContainsAny(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"Invoke-MimiKatz"}`),
+		[]byte(`{"a":"notepad.exe"}`),
+		[]byte(`{"a":""}`),
+		[]byte(`sekurlsa::logonpasswords`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringContainsAny(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"values":           []string{"mimikatz", "sekurlsa"},
+			"case_insensitive": true,
+			"metadata_key":     "matches",
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+func newStringEntropyGreaterThan(_ context.Context, cfg config.Config) (*stringEntropyGreaterThan, error) {
+	conf := stringEntropyConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	insp := stringEntropyGreaterThan{
+		conf: conf,
+	}
+
+	return &insp, nil
+}
+
+type stringEntropyGreaterThan struct {
+	conf stringEntropyConfig
+}
+
+// Condition returns true if the Shannon entropy of the string is greater than
+// the value.
+func (insp *stringEntropyGreaterThan) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	if insp.conf.Object.SourceKey == "" {
+		return stringEntropy(string(msg.Data())) > insp.conf.Value, nil
+	}
+
+	value := msg.GetValue(insp.conf.Object.SourceKey)
+	return stringEntropy(value.String()) > insp.conf.Value, nil
+}
+
+func (insp *stringEntropyGreaterThan) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
@@ -0,0 +1,147 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringEntropyGreaterThan{}
+
+var stringEntropyGreaterThanTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"value": 3.5,
+			},
+		},
+		[]byte(`{"a":"xj4kq9zt2vbw8rmp.com"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"value": 1,
+			},
+		},
+		[]byte(`abcd`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"value": 3.5,
+			},
+		},
+		[]byte(`{"a":"google.com"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"value": 0,
+			},
+		},
+		[]byte(`aaaa`),
+		false,
+	},
+}
+
+func TestStringEntropyGreaterThan(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringEntropyGreaterThanTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringEntropyGreaterThan(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkStringEntropyGreaterThanByte(b *testing.B, insp *stringEntropyGreaterThan, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringEntropyGreaterThanByte(b *testing.B) {
+	for _, test := range stringEntropyGreaterThanTests {
+		insp, err := newStringEntropyGreaterThan(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringEntropyGreaterThanByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringEntropyGreaterThan(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"xj4kq9zt2vbw8rmp.com"}`),
+		[]byte(`{"a":"google.com"}`),
+		[]byte(`{"a":""}`),
+		[]byte(`aaaa`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringEntropyGreaterThan(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"value": 3.5,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/kv"
+)
+
+type stringInBloomFilterConfig struct {
+	Object iconfig.Object `json:"object"`
+
+	// KVStore is the bloom_filter KV store that is used for membership checks.
+	KVStore config.Config `json:"kv_store"`
+}
+
+func (c *stringInBloomFilterConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *stringInBloomFilterConfig) Validate() error {
+	if c.KVStore.Type == "" {
+		return fmt.Errorf("kv_store: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.KVStore.Type != "bloom_filter" {
+		return fmt.Errorf("kv_store: %v", iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newStringInBloomFilter(_ context.Context, cfg config.Config) (*stringInBloomFilter, error) {
+	conf := stringInBloomFilterConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	kvStore, err := kv.Get(conf.KVStore)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := stringInBloomFilter{
+		conf:    conf,
+		kvStore: kvStore,
+	}
+
+	return &insp, nil
+}
+
+type stringInBloomFilter struct {
+	conf stringInBloomFilterConfig
+
+	kvStore kv.Storer
+}
+
+// Condition returns true if the value is probably in the set and false if the
+// value is definitely not in the set.
+func (insp *stringInBloomFilter) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	if !insp.kvStore.IsEnabled() {
+		if err := insp.kvStore.Setup(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	var key string
+	if insp.conf.Object.SourceKey == "" {
+		key = string(msg.Data())
+	} else {
+		key = msg.GetValue(insp.conf.Object.SourceKey).String()
+	}
+
+	v, err := insp.kvStore.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	ok, _ := v.(bool)
+	return ok, nil
+}
+
+func (c *stringInBloomFilter) String() string {
+	b, _ := json.Marshal(c.conf)
+	return string(b)
+}
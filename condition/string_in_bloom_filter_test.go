@@ -0,0 +1,150 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringInBloomFilter{}
+
+// stringInBloomFilterTestConfig returns a config that uses a Bloom filter built
+// from a text file that contains "foo" and "bar".
+func stringInBloomFilterTestConfig(tb testing.TB, settings map[string]interface{}) config.Config {
+	tb.Helper()
+
+	f := filepath.Join(tb.TempDir(), "items.txt")
+	if err := os.WriteFile(f, []byte("foo\nbar\n"), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+
+	s := map[string]interface{}{
+		"kv_store": map[string]interface{}{
+			"type": "bloom_filter",
+			"settings": map[string]interface{}{
+				"file": f,
+			},
+		},
+	}
+
+	for k, v := range settings {
+		s[k] = v
+	}
+
+	return config.Config{Settings: s}
+}
+
+var stringInBloomFilterTests = []struct {
+	name     string
+	settings map[string]interface{}
+	data     []byte
+	expected bool
+}{
+	{
+		"data",
+		nil,
+		[]byte("foo"),
+		true,
+	},
+	{
+		"object",
+		map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+		},
+		[]byte(`{"a":"bar"}`),
+		true,
+	},
+	{
+		"fail",
+		nil,
+		[]byte("baz"),
+		false,
+	},
+}
+
+func TestStringInBloomFilter(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringInBloomFilterTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.data)
+
+			insp, err := newStringInBloomFilter(ctx, stringInBloomFilterTestConfig(t, test.settings))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v", test.expected, check)
+			}
+		})
+	}
+}
+
+func benchmarkStringInBloomFilter(b *testing.B, insp *stringInBloomFilter, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringInBloomFilter(b *testing.B) {
+	for _, test := range stringInBloomFilterTests {
+		insp, err := newStringInBloomFilter(context.TODO(), stringInBloomFilterTestConfig(b, test.settings))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.data)
+				benchmarkStringInBloomFilter(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringInBloomFilter(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"foo"}`),
+		[]byte(`foo`),
+		[]byte(`{"a":"baz"}`),
+		[]byte(`baz`),
+		[]byte(`{"a":""}`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringInBloomFilter(context.TODO(), stringInBloomFilterTestConfig(f, map[string]interface{}{
+		"object": map[string]interface{}{
+			"source_key": "a",
+		},
+	}))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		message := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, message)
+		if err != nil {
+			return
+		}
+	})
+}
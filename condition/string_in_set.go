@@ -0,0 +1,167 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/kv"
+)
+
+type stringInSetConfig struct {
+	// Values is the set of strings that are used for membership checks.
+	Values []string `json:"values"`
+	// KVStore is a KV store that is used for membership checks instead of Values.
+	// A value is in the set if the key exists in the store and, if the item is a
+	// boolean (e.g., text_file), the item is true.
+	KVStore config.Config `json:"kv_store"`
+	// CaseInsensitive determines whether the membership check ignores case. If
+	// this is used with a KV store, then keys are converted to lowercase before
+	// they are retrieved from the store.
+	//
+	// This is optional and defaults to false.
+	CaseInsensitive bool `json:"case_insensitive"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *stringInSetConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *stringInSetConfig) Validate() error {
+	if len(c.Values) == 0 && c.KVStore.Type == "" {
+		return fmt.Errorf("values: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if len(c.Values) > 0 && c.KVStore.Type != "" {
+		return fmt.Errorf("values and kv_store: %v", iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newStringInSet(_ context.Context, cfg config.Config) (*stringInSet, error) {
+	conf := stringInSetConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	insp := stringInSet{
+		conf: conf,
+	}
+
+	if conf.KVStore.Type != "" {
+		kvStore, err := kv.Get(conf.KVStore)
+		if err != nil {
+			return nil, err
+		}
+
+		insp.kvStore = kvStore
+		return &insp, nil
+	}
+
+	insp.set = make(map[string]struct{}, len(conf.Values))
+	for _, v := range conf.Values {
+		insp.set[insp.key(v)] = struct{}{}
+	}
+
+	return &insp, nil
+}
+
+type stringInSet struct {
+	conf stringInSetConfig
+
+	set     map[string]struct{}
+	kvStore kv.Storer
+}
+
+// Condition returns true if the value is in the set. If the value is an array,
+// then the condition returns true if any element in the array is in the set.
+func (insp *stringInSet) Condition(ctx context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var keys []string
+	if insp.conf.Object.SourceKey == "" {
+		keys = append(keys, insp.key(string(msg.Data())))
+	} else {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		if value.IsArray() {
+			for _, v := range value.Array() {
+				keys = append(keys, insp.key(v.String()))
+			}
+		} else {
+			keys = append(keys, insp.key(value.String()))
+		}
+	}
+
+	if insp.kvStore != nil {
+		return insp.inKVStore(ctx, keys)
+	}
+
+	for _, k := range keys {
+		if _, ok := insp.set[k]; ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (insp *stringInSet) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
+
+func (insp *stringInSet) key(s string) string {
+	if insp.conf.CaseInsensitive {
+		return strings.ToLower(s)
+	}
+
+	return s
+}
+
+// inKVStore returns true if any key is in the KV store.
+func (insp *stringInSet) inKVStore(ctx context.Context, keys []string) (bool, error) {
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	if !insp.kvStore.IsEnabled() {
+		if err := insp.kvStore.Setup(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	items, err := kv.GetBatch(ctx, insp.kvStore, keys)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range items {
+		if ok, isBool := v.(bool); isBool && !ok {
+			continue
+		}
+
+		if v != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
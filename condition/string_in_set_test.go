@@ -0,0 +1,221 @@
+package condition
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringInSet{}
+
+var stringInSetTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"values": []string{"foo", "bar"},
+			},
+		},
+		[]byte("foo"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":           []string{"foo", "bar"},
+				"case_insensitive": true,
+			},
+		},
+		[]byte(`{"a":"BAR"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values": []string{"foo", "bar"},
+			},
+		},
+		[]byte(`{"a":["baz","bar"]}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"values": []string{"foo", "bar"},
+			},
+		},
+		[]byte("FOO"),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values": []string{"foo", "bar"},
+			},
+		},
+		[]byte(`{"a":["baz","qux"]}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "b",
+				},
+				"values": []string{"foo", "bar"},
+			},
+		},
+		[]byte(`{"a":"foo"}`),
+		false,
+	},
+}
+
+func TestStringInSet(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringInSetTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringInSet(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestStringInSetKVStore(t *testing.T) {
+	ctx := context.TODO()
+
+	f := filepath.Join(t.TempDir(), "items.txt")
+	if err := os.WriteFile(f, []byte("foo\nbar\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	insp, err := newStringInSet(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"kv_store": map[string]interface{}{
+				"type": "text_file",
+				"settings": map[string]interface{}{
+					"file": f,
+				},
+			},
+			"case_insensitive": true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for data, expected := range map[string]bool{
+		`{"a":"foo"}`:         true,
+		`{"a":"BAR"}`:         true,
+		`{"a":["baz","foo"]}`: true,
+		`{"a":"baz"}`:         false,
+		`{"a":[]}`:            false,
+	} {
+		check, err := insp.Condition(ctx, message.New().SetData([]byte(data)))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if expected != check {
+			t.Errorf("expected %v, got %v, %v", expected, check, data)
+		}
+	}
+}
+
+func benchmarkStringInSetByte(b *testing.B, insp *stringInSet, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringInSetByte(b *testing.B) {
+	for _, test := range stringInSetTests {
+		insp, err := newStringInSet(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringInSetByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringInSet(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"foo"}`),
+		[]byte(`{"a":["bar","baz"]}`),
+		[]byte(`{"a":""}`),
+		[]byte(`foo`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringInSet(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"values":           []string{"foo", "bar"},
+			"case_insensitive": true,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package condition
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+func newStringIsBase64(_ context.Context, cfg config.Config) (*stringIsBase64, error) {
+	conf := stringEncodingConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if conf.MinLength == 0 {
+		conf.MinLength = 1
+	}
+
+	insp := stringIsBase64{
+		conf: conf,
+	}
+
+	return &insp, nil
+}
+
+type stringIsBase64 struct {
+	conf stringEncodingConfig
+}
+
+// Condition returns true if the string is base64 encoded. Standard and URL
+// encodings are supported, with or without padding.
+func (insp *stringIsBase64) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	if insp.conf.Object.SourceKey == "" {
+		return insp.match(string(msg.Data())), nil
+	}
+
+	value := msg.GetValue(insp.conf.Object.SourceKey)
+	return insp.match(value.String()), nil
+}
+
+func (insp *stringIsBase64) match(s string) bool {
+	if len(s) < insp.conf.MinLength {
+		return false
+	}
+
+	// Padding is removed so that padded and unpadded strings are decoded the same
+	// way. Padded strings contain at most two padding characters and are always
+	// a multiple of 4 characters long.
+	if strings.HasSuffix(s, "=") {
+		if len(s)%4 != 0 {
+			return false
+		}
+
+		s = strings.TrimSuffix(s, "=")
+		s = strings.TrimSuffix(s, "=")
+	}
+
+	if s == "" {
+		return false
+	}
+
+	if strings.ContainsAny(s, "-_") {
+		_, err := base64.RawURLEncoding.DecodeString(s)
+		return err == nil
+	}
+
+	_, err := base64.RawStdEncoding.DecodeString(s)
+	return err == nil
+}
+
+func (insp *stringIsBase64) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
@@ -0,0 +1,225 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringIsBase64{}
+
+var stringIsBase64Tests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+			},
+		},
+		[]byte(`{"a":"aGVsbG8gd29ybGQ="}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"min_length": 8,
+			},
+		},
+		[]byte(`{"a":"PD94bWwgdmVyc2lvbj0iMS4wIj8-"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aGVsbG8gd29ybGQ`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+			},
+		},
+		[]byte(`{"a":"hello world"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"min_length": 8,
+			},
+		},
+		[]byte(`{"a":"dGVzdA"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`a+b/c`),
+		false,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aGVsbG8=`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aGk=`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aA==`),
+		true,
+	},
+	// Padding longer than two characters is invalid.
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aGVsbG8====`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aA======`),
+		false,
+	},
+	// Padded strings must be a multiple of 4 characters long.
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`aGVsbG8==`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{},
+		},
+		[]byte(`====`),
+		false,
+	},
+}
+
+func TestStringIsBase64(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringIsBase64Tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringIsBase64(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkStringIsBase64Byte(b *testing.B, insp *stringIsBase64, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringIsBase64Byte(b *testing.B) {
+	for _, test := range stringIsBase64Tests {
+		insp, err := newStringIsBase64(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringIsBase64Byte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringIsBase64(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"aGVsbG8gd29ybGQ="}`),
+		[]byte(`{"a":"hello world"}`),
+		[]byte(`{"a":"===="}`),
+		[]byte(`aGVsbG8`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringIsBase64(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"min_length": 8,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+func newStringIsHex(_ context.Context, cfg config.Config) (*stringIsHex, error) {
+	conf := stringEncodingConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if conf.MinLength == 0 {
+		conf.MinLength = 1
+	}
+
+	insp := stringIsHex{
+		conf: conf,
+	}
+
+	return &insp, nil
+}
+
+type stringIsHex struct {
+	conf stringEncodingConfig
+}
+
+// Condition returns true if the string is hex encoded. Strings can have an
+// optional 0x prefix and must have an even number of hex digits.
+func (insp *stringIsHex) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	if insp.conf.Object.SourceKey == "" {
+		return insp.match(string(msg.Data())), nil
+	}
+
+	value := msg.GetValue(insp.conf.Object.SourceKey)
+	return insp.match(value.String()), nil
+}
+
+func (insp *stringIsHex) match(s string) bool {
+	if len(s) < insp.conf.MinLength {
+		return false
+	}
+
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" || len(s)%2 != 0 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (insp *stringIsHex) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
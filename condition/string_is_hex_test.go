@@ -0,0 +1,163 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringIsHex{}
+
+var stringIsHexTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+			},
+		},
+		[]byte(`{"a":"deadBEEF"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"min_length": 8,
+			},
+		},
+		[]byte(`{"a":"0x4d5a90000300"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+			},
+		},
+		[]byte(`{"a":"abc"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+			},
+		},
+		[]byte(`{"a":"0xzz"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"min_length": 8,
+			},
+		},
+		[]byte(`{"a":"cafe"}`),
+		false,
+	},
+}
+
+func TestStringIsHex(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringIsHexTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringIsHex(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkStringIsHexByte(b *testing.B, insp *stringIsHex, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringIsHexByte(b *testing.B) {
+	for _, test := range stringIsHexTests {
+		insp, err := newStringIsHex(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringIsHexByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringIsHex(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"deadbeef"}`),
+		[]byte(`{"a":"0x4d5a"}`),
+		[]byte(`{"a":"0x"}`),
+		[]byte(`cafe`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringIsHex(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"min_length": 8,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type stringSimilarToConfig struct {
+	// Values is a list of reference strings used for comparison during
+	// inspection (e.g., domains that are targeted by typosquatting).
+	Values []string `json:"values"`
+	// Algorithm is the algorithm that is used to compare strings.
+	//
+	// Must be one of:
+	//
+	// - jaro_winkler: similarity between 0 and 1, where 1 is an exact match
+	//
+	// - levenshtein: edit distance, where 0 is an exact match
+	//
+	// This is optional and defaults to jaro_winkler.
+	Algorithm string `json:"algorithm"`
+	// Threshold is the minimum similarity (jaro_winkler) or the maximum edit
+	// distance (levenshtein) for a string to be similar to a reference string.
+	//
+	// This is optional and defaults to 0.9 for jaro_winkler and 2 for levenshtein.
+	Threshold *float64 `json:"threshold"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *stringSimilarToConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *stringSimilarToConfig) Validate() error {
+	if len(c.Values) == 0 {
+		return fmt.Errorf("values: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	switch c.Algorithm {
+	case "jaro_winkler", "levenshtein":
+	default:
+		return fmt.Errorf("algorithm %s: %v", c.Algorithm, iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newStringSimilarTo(_ context.Context, cfg config.Config) (*stringSimilarTo, error) {
+	conf := stringSimilarToConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if conf.Algorithm == "" {
+		conf.Algorithm = "jaro_winkler"
+	}
+
+	// A threshold of 0 is valid for levenshtein (exact match), so the default
+	// is only used when the threshold is not set.
+	if conf.Threshold == nil {
+		threshold := 0.9
+		if conf.Algorithm == "levenshtein" {
+			threshold = 2
+		}
+
+		conf.Threshold = &threshold
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	insp := stringSimilarTo{
+		conf:   conf,
+		values: make([][]rune, len(conf.Values)),
+	}
+
+	for i, v := range conf.Values {
+		insp.values[i] = []rune(v)
+	}
+
+	return &insp, nil
+}
+
+type stringSimilarTo struct {
+	conf stringSimilarToConfig
+
+	values [][]rune
+}
+
+// Condition returns true if the string is similar to any of the reference strings.
+func (insp *stringSimilarTo) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var s []rune
+	if insp.conf.Object.SourceKey == "" {
+		s = []rune(string(msg.Data()))
+	} else {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		s = []rune(value.String())
+	}
+
+	threshold := *insp.conf.Threshold
+	for _, v := range insp.values {
+		if insp.conf.Algorithm == "levenshtein" {
+			if float64(stringLevenshtein(s, v)) <= threshold {
+				return true, nil
+			}
+
+			continue
+		}
+
+		if stringJaroWinkler(s, v) >= threshold {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (insp *stringSimilarTo) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
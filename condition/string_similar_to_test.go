@@ -0,0 +1,211 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &stringSimilarTo{}
+
+var stringSimilarToTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values": []string{"google.com", "example.com"},
+			},
+		},
+		[]byte(`{"a":"gooogle.com"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":    []string{"paypal.com"},
+				"algorithm": "levenshtein",
+				"threshold": 1,
+			},
+		},
+		[]byte(`{"a":"paypa1.com"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values": []string{"google.com", "example.com"},
+			},
+		},
+		[]byte(`{"a":"brex.com"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":    []string{"paypal.com"},
+				"algorithm": "levenshtein",
+				"threshold": 1,
+			},
+		},
+		[]byte(`{"a":"paypai1.com"}`),
+		false,
+	},
+	// A threshold of 0 only matches exact strings.
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":    []string{"paypal.com"},
+				"algorithm": "levenshtein",
+				"threshold": 0,
+			},
+		},
+		[]byte(`{"a":"paypal.com"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "a",
+				},
+				"values":    []string{"paypal.com"},
+				"algorithm": "levenshtein",
+				"threshold": 0,
+			},
+		},
+		[]byte(`{"a":"paypa1.com"}`),
+		false,
+	},
+}
+
+func TestStringSimilarTo(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range stringSimilarToTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newStringSimilarTo(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkStringSimilarToByte(b *testing.B, insp *stringSimilarTo, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkStringSimilarToByte(b *testing.B) {
+	for _, test := range stringSimilarToTests {
+		insp, err := newStringSimilarTo(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkStringSimilarToByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestStringSimilarTo(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"a":"gooogle.com"}`),
+		[]byte(`{"a":"brex.com"}`),
+		[]byte(`{"a":""}`),
+		[]byte(`google.com`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newStringSimilarTo(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "a",
+			},
+			"values": []string{"google.com"},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
+
+func TestStringSimilarity(t *testing.T) {
+	for _, test := range []struct {
+		a, b        string
+		levenshtein int
+		jaroWinkler float64
+	}{
+		{"kitten", "sitting", 3, 0.746},
+		{"MARTHA", "MARHTA", 2, 0.961},
+		{"DWAYNE", "DUANE", 2, 0.840},
+		{"DIXON", "DICKSONX", 4, 0.813},
+		{"", "abc", 3, 0},
+		{"abc", "abc", 0, 1},
+	} {
+		if d := stringLevenshtein([]rune(test.a), []rune(test.b)); d != test.levenshtein {
+			t.Errorf("levenshtein %s %s: expected %d, got %d", test.a, test.b, test.levenshtein, d)
+		}
+
+		if s := stringJaroWinkler([]rune(test.a), []rune(test.b)); s < test.jaroWinkler-0.001 || s > test.jaroWinkler+0.001 {
+			t.Errorf("jaro_winkler %s %s: expected %.3f, got %.3f", test.a, test.b, test.jaroWinkler, s)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package condition
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type timeObjectConfig struct {
+	// CompareKey retrieves a timestamp from an object that is compared to the
+	// timestamp from SourceKey. If the key does not exist, then Value or Duration
+	// is used.
+	//
+	// This is optional and has no default.
+	CompareKey string `json:"compare_key"`
+
+	iconfig.Object
+}
+
+type timeConfig struct {
+	// Value is an absolute time in RFC 3339 format (e.g., 2024-01-01T00:00:00Z)
+	// used for comparison during inspection.
+	Value string `json:"value"`
+	// Duration is a time relative to the current time used for comparison during
+	// inspection. Positive durations are in the past and negative durations are
+	// in the future (e.g., 24h is 24 hours ago).
+	Duration string `json:"duration"`
+	// Format is the Go time layout of the timestamp (e.g., 2006-01-02T15:04:05Z07:00).
+	//
+	// This is optional and defaults to a Unix timestamp in nanoseconds, which is
+	// the format produced by the time_* transforms.
+	Format string `json:"format"`
+	// Location is the time zone that is used to parse timestamps that do not have
+	// a time zone. The value must be a location name corresponding to a file in the
+	// IANA Time Zone database (e.g., America/New_York).
+	//
+	// This is optional and defaults to UTC.
+	Location string `json:"location"`
+
+	Object timeObjectConfig `json:"object"`
+}
+
+func (c *timeConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *timeConfig) Validate() error {
+	if c.Value == "" && c.Duration == "" && c.Object.CompareKey == "" {
+		return fmt.Errorf("value: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Value != "" && c.Duration != "" {
+		return fmt.Errorf("value and duration: %v", iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// timeParser parses timestamps from messages.
+type timeParser struct {
+	format   string
+	location *time.Location
+}
+
+func newTimeParser(format, location string) (timeParser, error) {
+	p := timeParser{
+		format:   format,
+		location: time.UTC,
+	}
+
+	if location != "" {
+		loc, err := time.LoadLocation(location)
+		if err != nil {
+			return p, fmt.Errorf("location %s: %v", location, err)
+		}
+
+		p.location = loc
+	}
+
+	return p, nil
+}
+
+func (p timeParser) parse(s string) (time.Time, error) {
+	if p.format == "" {
+		ns, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unix timestamp %s: %v", s, err)
+		}
+
+		return time.Unix(0, ns), nil
+	}
+
+	t, err := time.ParseInLocation(p.format, s, p.location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("format %s: %v", p.format, err)
+	}
+
+	return t, nil
+}
+
+// timeCompare contains the timestamps that are compared by the time_before and
+// time_after conditions.
+type timeCompare struct {
+	conf   timeConfig
+	parser timeParser
+
+	value    time.Time
+	duration time.Duration
+}
+
+func newTimeCompare(conf timeConfig) (timeCompare, error) {
+	parser, err := newTimeParser(conf.Format, conf.Location)
+	if err != nil {
+		return timeCompare{}, err
+	}
+
+	c := timeCompare{
+		conf:   conf,
+		parser: parser,
+	}
+
+	if conf.Value != "" {
+		v, err := time.Parse(time.RFC3339, conf.Value)
+		if err != nil {
+			return c, fmt.Errorf("value: %v", err)
+		}
+
+		c.value = v
+	}
+
+	if conf.Duration != "" {
+		d, err := time.ParseDuration(conf.Duration)
+		if err != nil {
+			return c, fmt.Errorf("duration: %v", err)
+		}
+
+		c.duration = d
+	}
+
+	return c, nil
+}
+
+// get returns the timestamp from the message and the timestamp that it is
+// compared to. If the message does not contain a timestamp, then ok is false.
+func (c timeCompare) get(msg *message.Message) (ts, compare time.Time, ok bool, err error) {
+	var str string
+	if c.conf.Object.SourceKey == "" {
+		str = string(msg.Data())
+	} else {
+		value := msg.GetValue(c.conf.Object.SourceKey)
+		if !value.Exists() {
+			return ts, compare, false, nil
+		}
+
+		str = value.String()
+	}
+
+	ts, err = c.parser.parse(str)
+	if err != nil {
+		return ts, compare, false, err
+	}
+
+	switch {
+	case c.conf.Object.CompareKey != "" && msg.GetValue(c.conf.Object.CompareKey).Exists():
+		compare, err = c.parser.parse(msg.GetValue(c.conf.Object.CompareKey).String())
+		if err != nil {
+			return ts, compare, false, err
+		}
+	case c.conf.Value != "":
+		compare = c.value
+	case c.conf.Duration != "":
+		compare = time.Now().Add(-c.duration)
+	default:
+		return ts, compare, false, nil
+	}
+
+	return ts, compare, true, nil
+}
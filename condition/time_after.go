@@ -0,0 +1,59 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+func newTimeAfter(_ context.Context, cfg config.Config) (*timeAfter, error) {
+	conf := timeConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := newTimeCompare(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := timeAfter{
+		conf:    conf,
+		compare: c,
+	}
+
+	return &insp, nil
+}
+
+type timeAfter struct {
+	conf timeConfig
+
+	compare timeCompare
+}
+
+// Condition returns true if the timestamp is after the compared time. If the
+// duration is used, then this returns true if the timestamp is newer than the
+// duration.
+func (insp *timeAfter) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	ts, compare, ok, err := insp.compare.get(msg)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return ts.After(compare), nil
+}
+
+func (insp *timeAfter) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
@@ -0,0 +1,178 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &timeAfter{}
+
+var timeAfterTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"value": "2024-01-01T00:00:00Z",
+			},
+		},
+		[]byte("1735689600000000000"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"duration": "24h",
+				"format":   "2006-01-02 15:04:05",
+				"location": "America/New_York",
+			},
+		},
+		[]byte(`{"ts":"2999-01-01 00:00:00"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"value": "2024-01-01T00:00:00Z",
+			},
+		},
+		[]byte("1672531200000000000"),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"duration": "24h",
+				"format":   "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2020-01-01T00:00:00Z"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key":  "a",
+					"compare_key": "b",
+				},
+				"format": "2006-01-02",
+			},
+		},
+		[]byte(`{"a":"2024-01-01","b":"2024-01-02"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"duration": "24h",
+			},
+		},
+		[]byte(`{"a":"b"}`),
+		false,
+	},
+}
+
+func TestTimeAfter(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range timeAfterTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newTimeAfter(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkTimeAfterByte(b *testing.B, insp *timeAfter, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkTimeAfterByte(b *testing.B) {
+	for _, test := range timeAfterTests {
+		insp, err := newTimeAfter(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkTimeAfterByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestTimeAfter(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"ts":"2020-01-01T00:00:00Z"}`),
+		[]byte(`{"ts":"2999-01-01T00:00:00Z"}`),
+		[]byte(`{"ts":"invalid"}`),
+		[]byte(`1672531200000000000`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newTimeAfter(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "ts",
+			},
+			"duration": "24h",
+			"format":   "2006-01-02T15:04:05Z07:00",
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+// timeWithinDays maps day names and abbreviations to days of the week.
+var timeWithinDays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+type timeWithinConfig struct {
+	// Start is the time of day when the window begins, in HH:MM or HH:MM:SS format.
+	//
+	// This is optional and defaults to 00:00.
+	Start string `json:"start"`
+	// End is the time of day when the window ends (exclusive), in HH:MM or HH:MM:SS
+	// format. If End is earlier than Start, then the window continues past
+	// midnight (e.g., 22:00 to 06:00).
+	//
+	// This is optional and defaults to the end of the day.
+	End string `json:"end"`
+	// Days are the days of the week when the window begins (e.g., monday, tue).
+	//
+	// This is optional and defaults to every day.
+	Days []string `json:"days"`
+	// TimeZone is the time zone that the window is evaluated in. The value must be
+	// a location name corresponding to a file in the IANA Time Zone database (e.g.,
+	// America/New_York).
+	//
+	// This is optional and defaults to UTC.
+	TimeZone string `json:"time_zone"`
+	// Format is the Go time layout of the timestamp (e.g., 2006-01-02T15:04:05Z07:00).
+	//
+	// This is optional and defaults to a Unix timestamp in nanoseconds, which is
+	// the format produced by the time_* transforms.
+	Format string `json:"format"`
+	// Location is the time zone that is used to parse timestamps that do not have
+	// a time zone.
+	//
+	// This is optional and defaults to UTC.
+	Location string `json:"location"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *timeWithinConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func newTimeWithin(_ context.Context, cfg config.Config) (*timeWithin, error) {
+	conf := timeWithinConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	parser, err := newTimeParser(conf.Format, conf.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	insp := timeWithin{
+		conf:     conf,
+		parser:   parser,
+		end:      24 * time.Hour,
+		timeZone: time.UTC,
+	}
+
+	if conf.Start != "" {
+		if insp.start, err = timeWithinParseClock(conf.Start); err != nil {
+			return nil, fmt.Errorf("start: %v", err)
+		}
+	}
+
+	if conf.End != "" {
+		if insp.end, err = timeWithinParseClock(conf.End); err != nil {
+			return nil, fmt.Errorf("end: %v", err)
+		}
+	}
+
+	if conf.TimeZone != "" {
+		if insp.timeZone, err = time.LoadLocation(conf.TimeZone); err != nil {
+			return nil, fmt.Errorf("time_zone %s: %v", conf.TimeZone, err)
+		}
+	}
+
+	for _, d := range conf.Days {
+		day, ok := timeWithinDays[strings.ToLower(d)]
+		if !ok {
+			return nil, fmt.Errorf("days: %s: %v", d, iconfig.ErrInvalidOption)
+		}
+
+		insp.days[day] = true
+	}
+
+	if len(conf.Days) == 0 {
+		for i := range insp.days {
+			insp.days[i] = true
+		}
+	}
+
+	return &insp, nil
+}
+
+type timeWithin struct {
+	conf timeWithinConfig
+
+	parser   timeParser
+	timeZone *time.Location
+	// start and end are offsets from midnight.
+	start time.Duration
+	end   time.Duration
+	days  [7]bool
+}
+
+// Condition returns true if the timestamp is within the window.
+func (insp *timeWithin) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var str string
+	if insp.conf.Object.SourceKey == "" {
+		str = string(msg.Data())
+	} else {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		str = value.String()
+	}
+
+	ts, err := insp.parser.parse(str)
+	if err != nil {
+		return false, err
+	}
+
+	ts = ts.In(insp.timeZone)
+	clock := time.Duration(ts.Hour())*time.Hour +
+		time.Duration(ts.Minute())*time.Minute +
+		time.Duration(ts.Second())*time.Second +
+		time.Duration(ts.Nanosecond())
+
+	// The window is within a single day.
+	if insp.start <= insp.end {
+		return insp.days[ts.Weekday()] && clock >= insp.start && clock < insp.end, nil
+	}
+
+	// The window continues past midnight, so times after midnight belong to the
+	// window that began on the previous day.
+	if clock >= insp.start {
+		return insp.days[ts.Weekday()], nil
+	}
+
+	if clock < insp.end {
+		return insp.days[(ts.Weekday()+6)%7], nil
+	}
+
+	return false, nil
+}
+
+func (insp *timeWithin) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
+
+// timeWithinParseClock returns the offset from midnight of a time of day in
+// HH:MM or HH:MM:SS format. 24:00 is the end of the day.
+func timeWithinParseClock(s string) (time.Duration, error) {
+	if s == "24:00" || s == "24:00:00" {
+		return 24 * time.Hour, nil
+	}
+
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second, nil
+}
@@ -0,0 +1,196 @@
+package condition
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &timeWithin{}
+
+var timeWithinTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	// 2024-01-01 is a Monday.
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"start":  "09:00",
+				"end":    "17:00",
+				"days":   []string{"monday", "Tue"},
+				"format": "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2024-01-01T12:00:00Z"}`),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"start":     "09:00",
+				"end":       "17:00",
+				"time_zone": "America/New_York",
+			},
+		},
+		// 2024-01-01T15:00:00Z is 10:00 in New York.
+		[]byte("1704121200000000000"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"start":  "22:00",
+				"end":    "06:00",
+				"days":   []string{"sunday"},
+				"format": "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2024-01-01T02:00:00Z"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"start":  "09:00",
+				"end":    "17:00",
+				"days":   []string{"monday"},
+				"format": "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2024-01-01T17:00:00Z"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"days":   []string{"sat", "sun"},
+				"format": "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2024-01-01T12:00:00Z"}`),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "ts",
+				},
+				"start":  "22:00",
+				"end":    "06:00",
+				"days":   []string{"monday"},
+				"format": "2006-01-02T15:04:05Z07:00",
+			},
+		},
+		[]byte(`{"ts":"2024-01-01T02:00:00Z"}`),
+		false,
+	},
+}
+
+func TestTimeWithin(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range timeWithinTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newTimeWithin(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func benchmarkTimeWithinByte(b *testing.B, insp *timeWithin, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkTimeWithinByte(b *testing.B) {
+	for _, test := range timeWithinTests {
+		insp, err := newTimeWithin(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkTimeWithinByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestTimeWithin(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"ts":"2024-01-01T12:00:00Z"}`),
+		[]byte(`{"ts":"2024-01-01T02:00:00Z"}`),
+		[]byte(`{"ts":"invalid"}`),
+		[]byte(`1704121200000000000`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newTimeWithin(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "ts",
+			},
+			"start":  "22:00",
+			"end":    "06:00",
+			"days":   []string{"sunday"},
+			"format": "2006-01-02T15:04:05Z07:00",
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
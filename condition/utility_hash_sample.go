@@ -0,0 +1,111 @@
+package condition
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type utilityHashSampleConfig struct {
+	// Percentage is the percentage of keys that are sampled. Must be between 0
+	// and 100.
+	Percentage *float64 `json:"percentage"`
+	// Seed is combined with the key before it is hashed. Conditions that use
+	// different seeds sample different keys.
+	//
+	// This is optional and defaults to no seed.
+	Seed string `json:"seed"`
+
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *utilityHashSampleConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *utilityHashSampleConfig) Validate() error {
+	// A percentage of 0 is valid (no keys are sampled), so the percentage
+	// is only missing when it is not set.
+	if c.Percentage == nil {
+		return fmt.Errorf("percentage: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if *c.Percentage < 0 || *c.Percentage > 100 {
+		return fmt.Errorf("percentage %v: %v", *c.Percentage, iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newUtilityHashSample(_ context.Context, cfg config.Config) (*utilityHashSample, error) {
+	conf := utilityHashSampleConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	insp := utilityHashSample{
+		conf: conf,
+	}
+
+	return &insp, nil
+}
+
+type utilityHashSample struct {
+	conf utilityHashSampleConfig
+}
+
+// Condition returns true if the key is sampled. The same key is always sampled
+// the same way, so results are consistent across processes and retries.
+func (insp *utilityHashSample) Condition(_ context.Context, msg *message.Message) (bool, error) {
+	if msg.IsControl() {
+		return false, nil
+	}
+
+	var b []byte
+	if insp.conf.Object.SourceKey == "" {
+		b = msg.Data()
+	} else {
+		value := msg.GetValue(insp.conf.Object.SourceKey)
+		if !value.Exists() {
+			return false, nil
+		}
+
+		b = []byte(value.String())
+	}
+
+	// The top 53 bits of the hash are converted to a float in the range [0, 1),
+	// which is the largest range that a float64 can exactly represent.
+	f := float64(insp.hash(b)>>11) / (1 << 53)
+
+	return f*100 < *insp.conf.Percentage, nil
+}
+
+// hash returns the hash of the key combined with the seed. The seed is prefixed
+// with its length so that different seeds and keys cannot produce the same input
+// (e.g., "a" + "bc" and "ab" + "c").
+func (insp *utilityHashSample) hash(key []byte) uint64 {
+	h := fnv.New64a()
+	if insp.conf.Seed != "" {
+		_ = binary.Write(h, binary.BigEndian, uint64(len(insp.conf.Seed)))
+		_, _ = h.Write([]byte(insp.conf.Seed))
+	}
+
+	_, _ = h.Write(key)
+	return h.Sum64()
+}
+
+func (insp *utilityHashSample) String() string {
+	b, _ := json.Marshal(insp.conf)
+	return string(b)
+}
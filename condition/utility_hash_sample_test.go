@@ -0,0 +1,247 @@
+package condition
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Conditioner = &utilityHashSample{}
+
+var utilityHashSampleTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected bool
+}{
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"percentage": 100,
+			},
+		},
+		[]byte("foo"),
+		true,
+	},
+	{
+		"pass",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "trace_id",
+				},
+				"percentage": 100,
+			},
+		},
+		[]byte(`{"trace_id":"abc"}`),
+		true,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"percentage": 0,
+			},
+		},
+		[]byte("foo"),
+		false,
+	},
+	{
+		"fail",
+		config.Config{
+			Settings: map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "trace_id",
+				},
+				"percentage": 100,
+			},
+		},
+		[]byte(`{"a":"abc"}`),
+		false,
+	},
+}
+
+func TestUtilityHashSample(t *testing.T) {
+	ctx := context.TODO()
+
+	for _, test := range utilityHashSampleTests {
+		t.Run(test.name, func(t *testing.T) {
+			message := message.New().SetData(test.test)
+			insp, err := newUtilityHashSample(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := insp.Condition(ctx, message)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if test.expected != check {
+				t.Errorf("expected %v, got %v, %v", test.expected, check, string(test.test))
+			}
+		})
+	}
+}
+
+func TestUtilityHashSampleConsistency(t *testing.T) {
+	ctx := context.TODO()
+
+	cfg := config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "user",
+			},
+			"percentage": 25,
+		},
+	}
+
+	a, err := newUtilityHashSample(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := newUtilityHashSample(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 10000
+
+	var sampled int
+	for i := 0; i < n; i++ {
+		data := []byte(fmt.Sprintf(`{"user":"%d","i":%d}`, i, i))
+
+		x, err := a.Condition(ctx, message.New().SetData(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The same key in a different message is sampled the same way.
+		y, err := b.Condition(ctx, message.New().SetData([]byte(fmt.Sprintf(`{"user":"%d"}`, i))))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if x != y {
+			t.Fatalf("expected %v, got %v, %s", x, y, string(data))
+		}
+
+		if x {
+			sampled++
+		}
+	}
+
+	if p := float64(sampled) / n * 100; math.Abs(p-25) > 2 {
+		t.Errorf("expected about 25%%, got %v%%", p)
+	}
+}
+
+func TestUtilityHashSampleValidate(t *testing.T) {
+	for _, settings := range []map[string]interface{}{
+		{"percentage": -1},
+		{"percentage": 101},
+		// Percentage is required.
+		{"seed": "a"},
+		nil,
+	} {
+		if _, err := newUtilityHashSample(context.TODO(), config.Config{Settings: settings}); err == nil {
+			t.Errorf("expected error, got nil: %v", settings)
+		}
+	}
+}
+
+func TestUtilityHashSampleSeed(t *testing.T) {
+	newInsp := func(seed string) *utilityHashSample {
+		insp, err := newUtilityHashSample(context.TODO(), config.Config{
+			Settings: map[string]interface{}{
+				"percentage": 50,
+				"seed":       seed,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return insp
+	}
+
+	// The seed and key are not concatenated, so moving bytes between them
+	// changes the hash.
+	if newInsp("a").hash([]byte("bc")) == newInsp("ab").hash([]byte("c")) {
+		t.Error("expected different hashes for seed a, key bc and seed ab, key c")
+	}
+
+	if newInsp("").hash([]byte("abc")) == newInsp("a").hash([]byte("bc")) {
+		t.Error("expected different hashes for no seed, key abc and seed a, key bc")
+	}
+
+	// The same seed and key always have the same hash.
+	if newInsp("a").hash([]byte("bc")) != newInsp("a").hash([]byte("bc")) {
+		t.Error("expected the same hash for seed a, key bc")
+	}
+}
+
+func benchmarkUtilityHashSampleByte(b *testing.B, insp *utilityHashSample, message *message.Message) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		_, _ = insp.Condition(ctx, message)
+	}
+}
+
+func BenchmarkUtilityHashSampleByte(b *testing.B) {
+	for _, test := range utilityHashSampleTests {
+		insp, err := newUtilityHashSample(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				message := message.New().SetData(test.test)
+				benchmarkUtilityHashSampleByte(b, insp, message)
+			},
+		)
+	}
+}
+
+func FuzzTestUtilityHashSample(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"trace_id":"abc"}`),
+		[]byte(`{"trace_id":123}`),
+		[]byte(`{"trace_id":""}`),
+		[]byte(`foo`),
+		[]byte(`""`),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	insp, err := newUtilityHashSample(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "trace_id",
+			},
+			"percentage": 50,
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := insp.Condition(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,122 @@
+// Package cidr provides a radix tree for longest prefix matching of IP addresses.
+package cidr
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ErrInvalidRange is returned when a value is not a valid CIDR range or IP address.
+var ErrInvalidRange = fmt.Errorf("invalid CIDR range")
+
+// Tree is a binary radix tree that supports longest prefix matching of IP
+// addresses. IPv4 and IPv6 ranges are stored in separate trees, and lookups
+// are O(n), where n is the number of bits in the address.
+//
+// IPv4-mapped IPv6 ranges and addresses (e.g., ::ffff:10.0.0.0/104) are
+// treated as IPv4.
+//
+// Tree is not safe for concurrent writes, but it is safe for concurrent
+// lookups after all ranges are inserted.
+type Tree struct {
+	v4 node
+	v6 node
+}
+
+type node struct {
+	children [2]*node
+	value    interface{}
+	isSet    bool
+}
+
+// InsertString parses a CIDR range or IP address and inserts it into the tree.
+// IP addresses are treated as single host ranges (/32 or /128).
+func (t *Tree) InsertString(s string, val interface{}) error {
+	s = strings.TrimSpace(s)
+
+	var prefix netip.Prefix
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return fmt.Errorf("%v: %v", ErrInvalidRange, err)
+		}
+
+		prefix = p
+	} else {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return fmt.Errorf("%v: %v", ErrInvalidRange, err)
+		}
+
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	t.Insert(prefix, val)
+	return nil
+}
+
+// Insert adds a range to the tree. If the range already exists, then its value
+// is replaced.
+func (t *Tree) Insert(prefix netip.Prefix, val interface{}) {
+	prefix = prefix.Masked()
+
+	// IPv4-mapped IPv6 ranges are stored as IPv4 ranges because addresses
+	// are unmapped during lookup.
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	addr := prefix.Addr()
+
+	n := t.root(addr)
+	b := addr.AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		bit := bitAt(b, i)
+		if n.children[bit] == nil {
+			n.children[bit] = &node{}
+		}
+
+		n = n.children[bit]
+	}
+
+	n.value = val
+	n.isSet = true
+}
+
+// Lookup returns the value of the longest range that contains the address. If no
+// range contains the address, then false is returned.
+func (t *Tree) Lookup(addr netip.Addr) (interface{}, bool) {
+	addr = addr.Unmap()
+
+	n := t.root(addr)
+	val, ok := n.value, n.isSet
+
+	b := addr.AsSlice()
+	for i := 0; i < addr.BitLen(); i++ {
+		n = n.children[bitAt(b, i)]
+		if n == nil {
+			break
+		}
+
+		if n.isSet {
+			val, ok = n.value, true
+		}
+	}
+
+	return val, ok
+}
+
+func (t *Tree) root(addr netip.Addr) *node {
+	if addr.Is4() {
+		return &t.v4
+	}
+
+	return &t.v6
+}
+
+// bitAt returns the bit at position i, where position 0 is the most
+// significant bit.
+func bitAt(b []byte, i int) int {
+	return int(b[i/8]>>(7-i%8)) & 1
+}
@@ -0,0 +1,94 @@
+package cidr
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	var tree Tree
+	for r, v := range map[string]string{
+		"10.0.0.0/8":            "a",
+		"10.1.0.0/16":           "b",
+		"192.168.1.1":           "c",
+		"2001:db8::/32":         "d",
+		"::ffff:172.16.0.0/108": "e",
+		"::ffff:8.8.8.8":        "f",
+	} {
+		if err := tree.InsertString(r, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		addr     string
+		expected interface{}
+	}{
+		{"10.2.3.4", "a"},
+		{"10.1.2.3", "b"},
+		{"192.168.1.1", "c"},
+		{"192.168.1.2", nil},
+		{"2001:db8::1", "d"},
+		{"2001:db9::1", nil},
+		// IPv4-mapped ranges match IPv4 addresses.
+		{"172.16.1.1", "e"},
+		{"172.32.1.1", nil},
+		{"8.8.8.8", "f"},
+		{"8.8.4.4", nil},
+		// IPv4-mapped addresses match IPv4 ranges.
+		{"::ffff:10.1.2.3", "b"},
+		{"::ffff:172.16.1.1", "e"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			v, ok := tree.Lookup(netip.MustParseAddr(test.addr))
+			if v != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, v)
+			}
+
+			if ok != (test.expected != nil) {
+				t.Errorf("expected %v, got %v", test.expected != nil, ok)
+			}
+		})
+	}
+}
+
+func TestTreeInsertString(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"10.0.0.0/8", nil},
+		{" 10.0.0.1 ", nil},
+		{"2001:db8::/32", nil},
+		{"10.0.0.0/33", ErrInvalidRange},
+		{"10.0.0", ErrInvalidRange},
+		{"", ErrInvalidRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			var tree Tree
+
+			err := tree.InsertString(test.in, nil)
+			if (err == nil) != (test.err == nil) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestTreeNilValue(t *testing.T) {
+	var tree Tree
+	tree.Insert(netip.MustParsePrefix("0.0.0.0/0"), nil)
+
+	// Ranges without a value are still matched.
+	if _, ok := tree.Lookup(netip.MustParseAddr("10.0.0.1")); !ok {
+		t.Error("expected match")
+	}
+
+	if _, ok := tree.Lookup(netip.MustParseAddr("2001:db8::1")); ok {
+		t.Error("expected no match")
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/aws/aws-xray-sdk-go/v2/instrumentation/awsv2"
 
 	"github.com/brexhq/substation/v2/config"
+
+	"github.com/brexhq/substation/v2/internal/tracing"
 )
 
 var (
@@ -156,6 +158,10 @@ func NewAWS(ctx context.Context, cfg AWS) (aws.Config, error) {
 		awsv2.AWSV2Instrumentor(&conf.APIOptions)
 	}
 
+	if tracing.IsEnabled() {
+		tracing.AppendAWSMiddleware(&conf.APIOptions)
+	}
+
 	return conf, err
 }
 
@@ -168,3 +174,38 @@ type GCP struct {
 	// https://cloud.google.com/iam/docs/full-resource-names
 	Resource string `json:"resource"`
 }
+
+type Azure struct {
+	// Resource is the Azure resource that the action will interact with.
+	// This uses the URL of the resource, such as
+	// https://my-account.blob.core.windows.net/my-container (Blob Storage)
+	// or sb://my-namespace.servicebus.windows.net/my-hub (Event Hubs).
+	Resource string `json:"resource"`
+	// ConnectionString authenticates requests using a shared access
+	// connection string instead of the default Azure credential chain.
+	// This is most commonly used with local emulators (e.g., Azurite).
+	//
+	// This is optional and supports secrets interpolation.
+	ConnectionString string `json:"connection_string"`
+}
+
+type Cache struct {
+	// Capacity is the maximum number of values that can be cached.
+	//
+	// This is optional and defaults to 1024.
+	Capacity int `json:"capacity"`
+	// TTL is the amount of time that a value is cached.
+	//
+	// This is optional and defaults to 1 minute.
+	TTL string `json:"ttl"`
+	// NegativeTTL is the amount of time that a missing (null) value is cached.
+	//
+	// This is optional and defaults to not caching missing values.
+	NegativeTTL string `json:"negative_ttl"`
+	// Metric is used to generate metrics for cache hits and misses. Hits
+	// and misses are sent as separate metrics that use the name as a prefix
+	// (e.g., "MyCache" becomes "MyCacheHits" and "MyCacheMisses").
+	//
+	// This is optional and has no default.
+	Metric Metric `json:"metric"`
+}
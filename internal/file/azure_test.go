@@ -0,0 +1,67 @@
+package file
+
+import (
+	"sync"
+	"testing"
+)
+
+// azuriteConnectionString is the well-known connection string for Azurite.
+const azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
+
+func TestAzureClient(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_CONNECTION_STRING", azuriteConnectionString)
+
+	// Clients are created concurrently and must be shared per storage account.
+	var wg sync.WaitGroup
+	clients := make([]interface{}, 16)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			c, err := azureClient("devstoreaccount1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			clients[i] = c
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, c := range clients {
+		if c != clients[0] {
+			t.Fatal("expected a shared client")
+		}
+	}
+}
+
+func TestAzureClientAccountMismatch(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_CONNECTION_STRING", azuriteConnectionString)
+
+	if _, err := azureClient("otheraccount"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAzureConnectionStringAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		cs       string
+		expected string
+	}{
+		{"account name", azuriteConnectionString, "devstoreaccount1"},
+		{"blob endpoint", "BlobEndpoint=https://myaccount.blob.core.windows.net/;SharedAccessSignature=sv=2022-11-02&sig=a", "myaccount"},
+		{"no account", "BlobEndpoint=http://127.0.0.1:10000/;SharedAccessSignature=sv=2022-11-02&sig=a", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if a := azureConnectionStringAccount(test.cs); a != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, a)
+			}
+		})
+	}
+}
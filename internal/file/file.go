@@ -5,12 +5,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
@@ -23,6 +27,9 @@ var (
 	httpClient    http.HTTP
 	s3downloader  *manager.Downloader
 	gcpdownloader *storage.Client
+	// Azure Blob Storage clients are scoped to a storage account.
+	azMu          sync.Mutex
+	azdownloaders = make(map[string]*azblob.Client)
 )
 
 // errEmptyFile is returned when Get is called but finds an empty file.
@@ -31,6 +38,10 @@ var errEmptyFile = fmt.Errorf("empty file found")
 // errNoFile is returned when Get is called but no file is found.
 var errNoFile = fmt.Errorf("no file found")
 
+// errAzureAccountMismatch is returned when the storage account in an Azure Blob
+// Storage location does not match the account in the connection string.
+var errAzureAccountMismatch = fmt.Errorf("storage account does not match AZURE_STORAGE_CONNECTION_STRING")
+
 /*
 Get retrieves a file from these locations (in order):
 
@@ -42,6 +53,13 @@ Get retrieves a file from these locations (in order):
 
 - GCP Storage
 
+- Azure Blob Storage
+
+Azure Blob Storage locations use the format "azure://account/container/blob". If
+the AZURE_STORAGE_CONNECTION_STRING environment variable is set, then it is used
+to authenticate requests and the account must match the connection string,
+otherwise the default Azure credential chain is used.
+
 If a file is found, then it is saved as a temporary local file and the name is returned. The caller is responsible for removing files when they are no longer needed; files should be removed even if an error occurs.
 */
 func Get(ctx context.Context, location string) (string, error) {
@@ -157,9 +175,109 @@ func Get(ctx context.Context, location string) (string, error) {
 		return dst.Name(), nil
 	}
 
+	//nolint: nestif // ignore nesting complexity
+	if strings.HasPrefix(location, "azure://") {
+		// "azure://account/container/blob" becomes ["account" "container" "blob"]
+		paths := strings.SplitN(strings.TrimPrefix(location, "azure://"), "/", 3)
+		if len(paths) != 3 {
+			return dst.Name(), fmt.Errorf("get %s: %v", location, errNoFile)
+		}
+
+		client, err := azureClient(paths[0])
+		if err != nil {
+			return dst.Name(), fmt.Errorf("get %s: %v", location, err)
+		}
+
+		// Download the file from Azure Blob Storage.
+		ctx = context.WithoutCancel(ctx)
+		size, err := client.DownloadFile(ctx, paths[1], paths[2], dst, nil)
+		if err != nil {
+			return dst.Name(), fmt.Errorf("get %s: %v", location, err)
+		}
+
+		if size == 0 {
+			return dst.Name(), fmt.Errorf("get %s: %v", location, errEmptyFile)
+		}
+
+		return dst.Name(), nil
+	}
+
 	return dst.Name(), fmt.Errorf("get %s: %v", location, errNoFile)
 }
 
+// azureClient returns a cached Azure Blob Storage client for a storage account.
+func azureClient(account string) (*azblob.Client, error) {
+	azMu.Lock()
+	defer azMu.Unlock()
+
+	if client, ok := azdownloaders[account]; ok {
+		return client, nil
+	}
+
+	var client *azblob.Client
+	if cs, ok := os.LookupEnv("AZURE_STORAGE_CONNECTION_STRING"); ok {
+		// The connection string determines the account that is used, so
+		// locations in other accounts cannot be retrieved.
+		if a := azureConnectionStringAccount(cs); a != "" && !strings.EqualFold(a, account) {
+			return nil, fmt.Errorf("%s: %v", account, errAzureAccountMismatch)
+		}
+
+		c, err := azblob.NewClientFromConnectionString(cs, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		client = c
+	} else {
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+		c, err := azblob.NewClient(serviceURL, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		client = c
+	}
+
+	azdownloaders[account] = client
+	return client, nil
+}
+
+// azureConnectionStringAccount returns the storage account from a connection
+// string. If the connection string has no AccountName, then the account is
+// parsed from the BlobEndpoint (e.g., https://account.blob.core.windows.net).
+func azureConnectionStringAccount(cs string) string {
+	var endpoint string
+	for _, kv := range strings.Split(cs, ";") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "accountname":
+			return strings.TrimSpace(v)
+		case "blobendpoint":
+			endpoint = strings.TrimSpace(v)
+		}
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+
+	if a, _, ok := strings.Cut(u.Hostname(), ".blob."); ok {
+		return a
+	}
+
+	return ""
+}
+
 type Path struct {
 	// Prefix prepends a value to the file path.
 	//
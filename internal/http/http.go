@@ -7,6 +7,9 @@ import (
 
 	"github.com/aws/aws-xray-sdk-go/v2/xray"
 	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/brexhq/substation/v2/internal/tracing"
 )
 
 // errHTTPInvalidPayload is returned by Post when it receives an unexpected payload interface.
@@ -23,9 +26,13 @@ type HTTP struct {
 	Client *retryablehttp.Client
 }
 
-// Setup creates a retryable HTTP client.
+// Setup creates a retryable HTTP client. If tracing is enabled, then requests create spans and propagate the trace context to the server.
 func (h *HTTP) Setup() {
 	h.Client = retryablehttp.NewClient()
+
+	if tracing.IsEnabled() {
+		h.Client.HTTPClient.Transport = otelhttp.NewTransport(h.Client.HTTPClient.Transport)
+	}
 }
 
 // EnableXRay replaces the standard retryable HTTP client with an AWS XRay client. This method can be used when making HTTP calls on AWS infrastructure and should be enabled by looking for the environment variable "AWS_XRAY_DAEMON_ADDRESS".
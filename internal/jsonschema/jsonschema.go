@@ -0,0 +1,112 @@
+// Package jsonschema validates JSON data using JSON Schema (draft 2020-12).
+package jsonschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+// errMissingSchema is returned when neither an inline schema nor a file is provided.
+var errMissingSchema = fmt.Errorf("missing schema")
+
+// resource is the location of the schema within the compiler.
+const resource = "schema.json"
+
+// Validator validates JSON data using a compiled schema. It is safe for concurrent use.
+type Validator struct {
+	schema *jsonschema.Schema
+}
+
+// New compiles a schema that is either provided inline or stored in a file. The
+// file can be either a path on local disk, an HTTP(S) URL, or an AWS S3 URL. If
+// the schema does not declare a draft ($schema), then draft 2020-12 is used.
+// The format keyword is asserted (e.g., "format": "date-time" rejects strings
+// that are not timestamps).
+func New(ctx context.Context, schema map[string]interface{}, location string) (*Validator, error) {
+	var b []byte
+	switch {
+	case schema != nil:
+		s, err := json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+
+		b = s
+	case location != "":
+		path, err := file.Get(ctx, location)
+		defer os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		b = s
+	default:
+		return nil, errMissingSchema
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	c.AssertFormat()
+	if err := c.AddResource(resource, doc); err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+
+	sch, err := c.Compile(resource)
+	if err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+
+	return &Validator{schema: sch}, nil
+}
+
+// Validate returns the validation errors for the data. If the data is valid,
+// then no errors are returned. An error is returned if the data is not JSON.
+func (v *Validator) Validate(b []byte) ([]string, error) {
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.schema.Validate(inst)
+	if err == nil {
+		return nil, nil
+	}
+
+	vErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+
+	var errs []string
+	for _, u := range vErr.BasicOutput().Errors {
+		if u.Error == nil {
+			continue
+		}
+
+		errs = append(errs, fmt.Sprintf("at '%s': %s", u.InstanceLocation, u.Error))
+	}
+
+	// Invalid data always has at least one error.
+	if len(errs) == 0 {
+		errs = append(errs, vErr.Error())
+	}
+
+	return errs, nil
+}
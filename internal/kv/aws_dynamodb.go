@@ -17,6 +17,22 @@ import (
 	iconfig "github.com/brexhq/substation/v2/internal/config"
 )
 
+const (
+	// awsDynamoDBBatchGetLimit is the maximum number of keys that can be
+	// retrieved in a single BatchGetItem call.
+	awsDynamoDBBatchGetLimit = 100
+	// awsDynamoDBBatchWriteLimit is the maximum number of items that can be
+	// added in a single BatchWriteItem call.
+	awsDynamoDBBatchWriteLimit = 25
+	// awsDynamoDBBatchRetries is the maximum number of times that unprocessed
+	// keys and items are retried.
+	awsDynamoDBBatchRetries = 5
+)
+
+// errAWSDynamoDBUnprocessed is returned when keys or items are still unprocessed
+// after all retries are exhausted.
+var errAWSDynamoDBUnprocessed = fmt.Errorf("unprocessed items remain after retries")
+
 // kvAWSDynamoDB is a read-write key-value store that is backed by an AWS DynamoDB table.
 //
 // This KV store supports per-item time-to-live (TTL) and has some limitations when
@@ -334,6 +350,259 @@ func (store *kvAWSDynamoDB) SetAddWithTTL(ctx context.Context, key string, val i
 	return nil
 }
 
+// GetBatch retrieves items from the DynamoDB table. Keys that are not in the table
+// are not included in the result.
+//
+// This method uses the BatchGetItem API call, which retrieves up to 100 items per
+// call. Unprocessed keys are retried with exponential backoff.
+func (store *kvAWSDynamoDB) GetBatch(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	// DynamoDB rejects batches that contain duplicate keys.
+	var unique []string
+	seen := make(map[string]struct{})
+	for _, k := range keys {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		unique = append(unique, k)
+	}
+
+	out := make(map[string]interface{})
+
+	ctx = context.WithoutCancel(ctx)
+	for i := 0; i < len(unique); i += awsDynamoDBBatchGetLimit {
+		end := min(i+awsDynamoDBBatchGetLimit, len(unique))
+
+		var attrs []map[string]types.AttributeValue
+		for _, k := range unique[i:end] {
+			a, err := store.key(k)
+			if err != nil {
+				return nil, err
+			}
+
+			attrs = append(attrs, a)
+		}
+
+		req := map[string]types.KeysAndAttributes{
+			store.AWS.ARN: {
+				Keys:           attrs,
+				ConsistentRead: aws.Bool(store.ConsistentRead),
+			},
+		}
+
+		for attempt := 0; len(req) > 0; attempt++ {
+			if attempt > awsDynamoDBBatchRetries {
+				return nil, errAWSDynamoDBUnprocessed
+			}
+
+			if attempt > 0 {
+				awsDynamoDBBackoff(attempt)
+			}
+
+			resp, err := store.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: req,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, items := range resp.Responses {
+				for _, item := range items {
+					var k string
+					if err := attributevalue.Unmarshal(item[store.Attributes.PartitionKey], &k); err != nil {
+						return nil, err
+					}
+
+					val, found := item[store.Attributes.Value]
+					if !found {
+						continue
+					}
+
+					var v interface{}
+					if err := attributevalue.Unmarshal(val, &v); err != nil {
+						return nil, err
+					}
+
+					out[k] = v
+				}
+			}
+
+			req = resp.UnprocessedKeys
+		}
+	}
+
+	return out, nil
+}
+
+// SetBatch adds items to the DynamoDB table. If an item has a non-zero TTL, then
+// the TTL attribute must be configured.
+//
+// This method uses the BatchWriteItem API call, which adds up to 25 items per call.
+// Unprocessed items are retried with exponential backoff.
+func (store *kvAWSDynamoDB) SetBatch(ctx context.Context, items []Item) error {
+	// DynamoDB rejects batches that contain duplicate keys, so only the
+	// last item for each key is added.
+	var unique []Item
+	idx := make(map[string]int)
+	for _, i := range items {
+		if n, ok := idx[i.Key]; ok {
+			unique[n] = i
+			continue
+		}
+
+		idx[i.Key] = len(unique)
+		unique = append(unique, i)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for i := 0; i < len(unique); i += awsDynamoDBBatchWriteLimit {
+		end := min(i+awsDynamoDBBatchWriteLimit, len(unique))
+
+		var reqs []types.WriteRequest
+		for _, item := range unique[i:end] {
+			attrs, err := store.item(item.Key, item.Value, item.TTL)
+			if err != nil {
+				return err
+			}
+
+			reqs = append(reqs, types.WriteRequest{
+				PutRequest: &types.PutRequest{
+					Item: attrs,
+				},
+			})
+		}
+
+		req := map[string][]types.WriteRequest{
+			store.AWS.ARN: reqs,
+		}
+
+		for attempt := 0; len(req) > 0; attempt++ {
+			if attempt > awsDynamoDBBatchRetries {
+				return errAWSDynamoDBUnprocessed
+			}
+
+			if attempt > 0 {
+				awsDynamoDBBackoff(attempt)
+			}
+
+			resp, err := store.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: req,
+			})
+			if err != nil {
+				return err
+			}
+
+			req = resp.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// SetIfNotExistsWithTTL adds an item to the DynamoDB table if the item does not exist
+// or the TTL has expired. If the item exists, then this returns ErrConditionFailed.
+func (store *kvAWSDynamoDB) SetIfNotExistsWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	cond := expression.AttributeNotExists(expression.Name(store.Attributes.PartitionKey))
+	if store.Attributes.TTL != "" {
+		cond = cond.Or(expression.Name(store.Attributes.TTL).LessThanEqual(expression.Value(time.Now().Unix())))
+	}
+
+	return store.putWithCondition(ctx, key, val, ttl, cond)
+}
+
+// CompareAndSetWithTTL replaces an item in the DynamoDB table if the item exists, the
+// TTL has not expired, and the current value is equal to the old value. If the old
+// value is nil, then the item is only replaced if it has no value. If the condition is
+// not met, then this returns ErrConditionFailed.
+func (store *kvAWSDynamoDB) CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error {
+	// Nil values are stored as the NULL type, which cannot be compared.
+	value := expression.Name(store.Attributes.Value)
+	cmp := value.Equal(expression.Value(old))
+	if old == nil {
+		cmp = expression.AttributeNotExists(value).Or(value.AttributeType(expression.Null))
+	}
+
+	cond := expression.AttributeExists(expression.Name(store.Attributes.PartitionKey)).And(cmp)
+	if store.Attributes.TTL != "" {
+		ttlName := expression.Name(store.Attributes.TTL)
+		cond = cond.And(expression.AttributeNotExists(ttlName).Or(ttlName.GreaterThan(expression.Value(time.Now().Unix()))))
+	}
+
+	return store.putWithCondition(ctx, key, val, ttl, cond)
+}
+
+func (store *kvAWSDynamoDB) putWithCondition(ctx context.Context, key string, val interface{}, ttl int64, cond expression.ConditionBuilder) error {
+	item, err := store.item(key, val, ttl)
+	if err != nil {
+		return err
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if _, err := store.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(store.AWS.ARN),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}); err != nil {
+		var ccf *types.ConditionalCheckFailedException
+		if errors.As(err, &ccf) {
+			return ErrConditionFailed
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// key returns the primary key of an item.
+func (store *kvAWSDynamoDB) key(key string) (map[string]types.AttributeValue, error) {
+	m := map[string]interface{}{
+		store.Attributes.PartitionKey: key,
+	}
+
+	if store.Attributes.SortKey != "" {
+		m[store.Attributes.SortKey] = "substation:kv_store"
+	}
+
+	return attributevalue.MarshalMap(m)
+}
+
+// item returns an item that contains the primary key, value, and optionally the TTL.
+func (store *kvAWSDynamoDB) item(key string, val interface{}, ttl int64) (map[string]types.AttributeValue, error) {
+	m := map[string]interface{}{
+		store.Attributes.PartitionKey: key,
+		store.Attributes.Value:        val,
+	}
+
+	if store.Attributes.SortKey != "" {
+		m[store.Attributes.SortKey] = "substation:kv_store"
+	}
+
+	if ttl != 0 {
+		if store.Attributes.TTL == "" {
+			return nil, iconfig.ErrMissingRequiredOption
+		}
+
+		m[store.Attributes.TTL] = ttl
+	}
+
+	return attributevalue.MarshalMap(m)
+}
+
+// awsDynamoDBBackoff sleeps for an exponentially increasing amount of time
+// before unprocessed keys or items are retried.
+func awsDynamoDBBackoff(attempt int) {
+	time.Sleep(time.Duration(1<<attempt) * 25 * time.Millisecond)
+}
+
 // IsEnabled returns true if the DynamoDB client is ready for use.
 func (store *kvAWSDynamoDB) IsEnabled() bool {
 	return store.client != nil
@@ -0,0 +1,548 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+// bboltBucket is the bucket that all items are stored in.
+var bboltBucket = []byte("substation")
+
+var (
+	// bboltMu protects bboltDBs.
+	bboltMu sync.Mutex
+	// bboltDBs contains every open database, mapped by file path. Databases are
+	// shared because a file can only be opened once per process, and the same
+	// file may be used by a Storer and a Locker.
+	bboltDBs = make(map[string]*bboltDB)
+)
+
+// kvBBolt is a read-write key-value store that is backed by a local bbolt
+// database file. Learn more about bbolt here: https://github.com/etcd-io/bbolt.
+//
+// This KV store persists data between runs of an application on a single host,
+// supports per-item time-to-live (TTL), locking, and conditional writes, and
+// periodically removes expired items and compacts the database file.
+type kvBBolt struct {
+	// File is the path to the database file on local disk. If the file does
+	// not exist, then it is created.
+	File string `json:"file"`
+	// CompactionInterval is the amount of time between removing expired items
+	// from the store and compacting the database file.
+	//
+	// This is optional and defaults to 1 hour.
+	CompactionInterval string `json:"compaction_interval"`
+
+	mu sync.Mutex
+	db *bboltDB
+}
+
+// bboltItem is the format that values are stored in.
+type bboltItem struct {
+	Value interface{} `json:"value"`
+	// TTL is the Unix time when the item expires. A zero value
+	// indicates that the item does not expire.
+	TTL int64 `json:"ttl"`
+}
+
+func (i bboltItem) expired() bool {
+	return i.TTL != 0 && i.TTL <= time.Now().Unix()
+}
+
+// Create a new bbolt KV store.
+func newKVBBolt(cfg config.Config) (*kvBBolt, error) {
+	var store kvBBolt
+	if err := iconfig.Decode(cfg.Settings, &store); err != nil {
+		return nil, err
+	}
+
+	if store.File == "" {
+		return nil, fmt.Errorf("kv: bbolt: file: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if store.CompactionInterval == "" {
+		store.CompactionInterval = "1h"
+	}
+
+	if _, err := time.ParseDuration(store.CompactionInterval); err != nil {
+		return nil, fmt.Errorf("kv: bbolt: compaction_interval: %v", err)
+	}
+
+	return &store, nil
+}
+
+func (store *kvBBolt) String() string {
+	return toString(store)
+}
+
+// Get retrieves a value from the store. If the value had a time-to-live (TTL)
+// configured when it was added and the TTL has passed, then nothing is returned.
+func (store *kvBBolt) Get(ctx context.Context, key string) (interface{}, error) {
+	var item bboltItem
+	if err := store.db.view(func(b *bolt.Bucket) error {
+		v := b.Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+
+		return json.Unmarshal(v, &item)
+	}); err != nil {
+		return nil, fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	if item.expired() {
+		return nil, nil
+	}
+
+	return item.Value, nil
+}
+
+// Set adds a value to the store.
+func (store *kvBBolt) Set(ctx context.Context, key string, val interface{}) error {
+	return store.SetWithTTL(ctx, key, val, 0)
+}
+
+// SetWithTTL adds a value to the store with a time-to-live (TTL). If the TTL
+// value is zero, then the value does not expire.
+func (store *kvBBolt) SetWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	b, err := json.Marshal(bboltItem{Value: val, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	if err := store.db.update(func(bkt *bolt.Bucket) error {
+		return bkt.Put([]byte(key), b)
+	}); err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return nil
+}
+
+// SetAddWithTTL appends a value to a set (unique list) in the store. If the set does
+// not exist or has expired, then it is created.
+//
+// If the TTL value is zero, then the item will not expire.
+func (store *kvBBolt) SetAddWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	vb, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	if err := store.db.update(func(bkt *bolt.Bucket) error {
+		// Values are decoded as raw JSON so that set members can be compared
+		// without knowing their type.
+		var item struct {
+			Value []json.RawMessage `json:"value"`
+			TTL   int64             `json:"ttl"`
+		}
+
+		if v := bkt.Get([]byte(key)); v != nil {
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+		}
+
+		if (bboltItem{TTL: item.TTL}).expired() {
+			item.Value = nil
+		}
+
+		// Always update the TTL value. Zero values are ignored on retrieval.
+		item.TTL = ttl
+
+		exists := false
+		for _, m := range item.Value {
+			if bytes.Equal(m, vb) {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			item.Value = append(item.Value, vb)
+		}
+
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		return bkt.Put([]byte(key), b)
+	}); err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return nil
+}
+
+// SetIfNotExistsWithTTL adds a value to the store with a time-to-live (TTL) if the
+// key does not exist or has expired. If the key exists, then this returns
+// ErrConditionFailed.
+func (store *kvBBolt) SetIfNotExistsWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	b, err := json.Marshal(bboltItem{Value: val, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	err = store.db.update(func(bkt *bolt.Bucket) error {
+		if v := bkt.Get([]byte(key)); v != nil {
+			var item bboltItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+
+			if !item.expired() {
+				return ErrConditionFailed
+			}
+		}
+
+		return bkt.Put([]byte(key), b)
+	})
+
+	if errors.Is(err, ErrConditionFailed) {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return nil
+}
+
+// CompareAndSetWithTTL replaces a value in the store with a time-to-live (TTL) if
+// the current value is equal to the old value. Values are compared using their
+// JSON encoding. If the values are not equal or the key does not exist, then this
+// returns ErrConditionFailed.
+func (store *kvBBolt) CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error {
+	ob, err := json.Marshal(old)
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	b, err := json.Marshal(bboltItem{Value: val, TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	err = store.db.update(func(bkt *bolt.Bucket) error {
+		v := bkt.Get([]byte(key))
+		if v == nil {
+			return ErrConditionFailed
+		}
+
+		// The value is decoded as raw JSON so that it can be compared
+		// without knowing its type.
+		var item struct {
+			Value json.RawMessage `json:"value"`
+			TTL   int64           `json:"ttl"`
+		}
+
+		if err := json.Unmarshal(v, &item); err != nil {
+			return err
+		}
+
+		if (bboltItem{TTL: item.TTL}).expired() || !bytes.Equal(item.Value, ob) {
+			return ErrConditionFailed
+		}
+
+		return bkt.Put([]byte(key), b)
+	})
+
+	if errors.Is(err, ErrConditionFailed) {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return nil
+}
+
+// Lock adds an item to the store if it does not already exist. If the item already exists
+// and the time-to-live (TTL) has not expired, then this returns ErrNoLock.
+func (store *kvBBolt) Lock(ctx context.Context, key string, ttl int64) error {
+	b, err := json.Marshal(bboltItem{TTL: ttl})
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return store.db.update(func(bkt *bolt.Bucket) error {
+		if v := bkt.Get([]byte(key)); v != nil {
+			var item bboltItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return fmt.Errorf("kv: bbolt: %v", err)
+			}
+
+			if !item.expired() {
+				return ErrNoLock
+			}
+		}
+
+		if err := bkt.Put([]byte(key), b); err != nil {
+			return fmt.Errorf("kv: bbolt: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// Unlock removes an item from the store.
+func (store *kvBBolt) Unlock(ctx context.Context, key string) error {
+	if err := store.db.update(func(bkt *bolt.Bucket) error {
+		return bkt.Delete([]byte(key))
+	}); err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	return nil
+}
+
+// IsEnabled returns true if the store is ready for use.
+func (store *kvBBolt) IsEnabled() bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.db != nil
+}
+
+// Setup creates the store by opening the database file.
+func (store *kvBBolt) Setup(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary setup
+	if store.db != nil {
+		return nil
+	}
+
+	// The interval is validated when the store is created.
+	interval, _ := time.ParseDuration(store.CompactionInterval)
+
+	db, err := openBBoltDB(store.File, interval)
+	if err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	store.db = db
+	return nil
+}
+
+// Closes the store.
+func (store *kvBBolt) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary closing
+	if store.db == nil {
+		return nil
+	}
+
+	if err := closeBBoltDB(store.db); err != nil {
+		return fmt.Errorf("kv: bbolt: %v", err)
+	}
+
+	store.db = nil
+	return nil
+}
+
+// bboltDB is a database that is shared by all stores that use the same file.
+type bboltDB struct {
+	// mu is held for writing when the database is compacted.
+	mu   sync.RWMutex
+	db   *bolt.DB
+	path string
+	refs int
+	done chan struct{}
+}
+
+func openBBoltDB(path string, interval time.Duration) (*bboltDB, error) {
+	bboltMu.Lock()
+	defer bboltMu.Unlock()
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if db, ok := bboltDBs[path]; ok {
+		db.refs++
+		return db, nil
+	}
+
+	bdb, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bdb.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bboltBucket)
+		return err
+	}); err != nil {
+		_ = bdb.Close()
+		return nil, err
+	}
+
+	db := &bboltDB{
+		db:   bdb,
+		path: path,
+		refs: 1,
+		done: make(chan struct{}),
+	}
+
+	go db.maintain(interval)
+	bboltDBs[path] = db
+
+	return db, nil
+}
+
+func closeBBoltDB(db *bboltDB) error {
+	bboltMu.Lock()
+	defer bboltMu.Unlock()
+
+	db.refs--
+	if db.refs > 0 {
+		return nil
+	}
+
+	close(db.done)
+	delete(bboltDBs, db.path)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.db.Close()
+}
+
+func (db *bboltDB) view(fn func(*bolt.Bucket) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(bboltBucket))
+	})
+}
+
+func (db *bboltDB) update(fn func(*bolt.Bucket) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(bboltBucket))
+	})
+}
+
+// maintain periodically removes expired items and compacts the database until
+// the database is closed. Errors are ignored because they do not affect the
+// correctness of the store and are retried on the next interval.
+func (db *bboltDB) maintain(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			_ = db.purge()
+			_ = db.compact()
+		}
+	}
+}
+
+// purge removes all expired items from the database.
+func (db *bboltDB) purge() error {
+	return db.update(func(bkt *bolt.Bucket) error {
+		var keys [][]byte
+		if err := bkt.ForEach(func(k, v []byte) error {
+			var item bboltItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+
+			if item.expired() {
+				keys = append(keys, bytes.Clone(k))
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := bkt.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// compact rewrites the database into a new file, which reclaims space that was
+// used by deleted items, and replaces the original file.
+func (db *bboltDB) compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// The database may have been closed while waiting for the lock.
+	select {
+	case <-db.done:
+		return nil
+	default:
+	}
+
+	tmp := db.path + ".compact"
+	dst, err := bolt.Open(tmp, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+
+	if err := bolt.Compact(dst, db.db, 0); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	// The compacted file is opened and moved into place before the original
+	// database is closed, so the original database remains usable if any step
+	// fails. Open files follow renames on Unix-like systems; on systems where
+	// open files cannot be replaced (e.g., Windows), the database is not compacted.
+	bdb, err := bolt.Open(tmp, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, db.path); err != nil {
+		_ = bdb.Close()
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	old := db.db
+	db.db = bdb
+
+	return old.Close()
+}
@@ -0,0 +1,230 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+func newTestKVBBolt(t *testing.T, file string) *kvBBolt {
+	t.Helper()
+
+	store, err := newKVBBolt(config.Config{
+		Settings: map[string]interface{}{
+			"file": file,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Setup(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestKVBBoltTTL(t *testing.T) {
+	ctx := context.TODO()
+
+	store := newTestKVBBolt(t, filepath.Join(t.TempDir(), "kv.db"))
+	defer store.Close()
+
+	tests := []struct {
+		name     string
+		ttl      int64
+		expected interface{}
+	}{
+		{"no ttl", 0, "b"},
+		{"not expired", time.Now().Add(time.Hour).Unix(), "b"},
+		{"expired", time.Now().Add(-time.Second).Unix(), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := store.SetWithTTL(ctx, test.name, "b", test.ttl); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := store.Get(ctx, test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestKVBBoltLock(t *testing.T) {
+	ctx := context.TODO()
+
+	store := newTestKVBBolt(t, filepath.Join(t.TempDir(), "kv.db"))
+	defer store.Close()
+
+	ttl := time.Now().Add(time.Hour).Unix()
+	if err := store.Lock(ctx, "a", ttl); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Lock(ctx, "a", ttl); !errors.Is(err, ErrNoLock) {
+		t.Fatalf("expected %v, got %v", ErrNoLock, err)
+	}
+
+	if err := store.Unlock(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Lock(ctx, "a", ttl); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expired locks can be acquired.
+	if err := store.Lock(ctx, "b", time.Now().Add(-time.Second).Unix()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Lock(ctx, "b", ttl); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKVBBoltReopen(t *testing.T) {
+	ctx := context.TODO()
+	file := filepath.Join(t.TempDir(), "kv.db")
+
+	store := newTestKVBBolt(t, file)
+	if err := store.Set(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetAddWithTTL(ctx, "c", "d", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store = newTestKVBBolt(t, file)
+	defer store.Close()
+
+	v, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v != "b" {
+		t.Errorf("expected b, got %v", v)
+	}
+
+	v, err = store.Get(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(v) != "[d]" {
+		t.Errorf("expected [d], got %v", v)
+	}
+}
+
+func TestKVBBoltCompact(t *testing.T) {
+	ctx := context.TODO()
+	file := filepath.Join(t.TempDir(), "kv.db")
+
+	store := newTestKVBBolt(t, file)
+	defer store.Close()
+
+	expired := time.Now().Add(-time.Second).Unix()
+	for i := 0; i < 1000; i++ {
+		if err := store.SetWithTTL(ctx, fmt.Sprintf("expired-%d", i), "value", expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Set(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.db.purge(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.db.compact(); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if after.Size() >= before.Size() {
+		t.Errorf("expected file to shrink, got %d >= %d", after.Size(), before.Size())
+	}
+
+	// The store is usable after compaction.
+	if err := store.Set(ctx, "c", "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]interface{}{"a": "b", "c": "d", "expired-0": nil} {
+		v, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != expected {
+			t.Errorf("expected %v, got %v", expected, v)
+		}
+	}
+}
+
+func TestKVBBoltCompactFailure(t *testing.T) {
+	ctx := context.TODO()
+	file := filepath.Join(t.TempDir(), "kv.db")
+
+	store := newTestKVBBolt(t, file)
+	defer store.Close()
+
+	if err := store.Set(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in place of the temporary file causes compaction to fail.
+	if err := os.Mkdir(file+".compact", 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.db.compact(); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	// The store is usable after a failed compaction.
+	if err := store.Set(ctx, "c", "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v != "b" {
+		t.Errorf("expected b, got %v", v)
+	}
+}
@@ -0,0 +1,341 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bits-and-blooms/bloom/v3"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+// errBloomFilterInvalidFalsePositiveRate is returned when the false positive rate
+// is not between 0 and 1.
+var errBloomFilterInvalidFalsePositiveRate = fmt.Errorf("false positive rate must be between 0 and 1")
+
+// kvBloomFilter is a read-only key-value store that is derived from a file and
+// stored in memory as a Bloom filter. Learn more about Bloom filters here:
+// https://en.wikipedia.org/wiki/Bloom_filter.
+//
+// Bloom filters are probabilistic sets that use a small, fixed amount of memory
+// regardless of the size of the items in the set. Retrieving a key returns true if
+// the key is probably in the set and false if the key is definitely not in the set,
+// which makes this store useful for removing non-matching data before querying a
+// more expensive store.
+//
+// The filter can be built from a text file (one item per line), a CSV file (one
+// item per row in a column), or loaded from a filter that was serialized using the
+// WriteTo method from https://github.com/bits-and-blooms/bloom.
+type kvBloomFilter struct {
+	// File contains the location of the file. This can be either a path on local
+	// disk, an HTTP(S) URL, or an AWS S3 URL.
+	File string `json:"file"`
+	// Format is the format of the file. Must be one of:
+	//
+	// - text: newline delimited items
+	//
+	// - csv: items are loaded from a column
+	//
+	// - filter: a serialized Bloom filter
+	//
+	// This is optional and defaults to text.
+	Format string `json:"format"`
+	// Column determines which values from the CSV file are loaded into the filter.
+	// This is required for CSV files.
+	Column string `json:"column"`
+	// Delimiter is the delimiting character (e.g., comma, tab) that separates values
+	// in rows in the CSV file.
+	//
+	// This is optional and defaults to comma (",").
+	Delimiter string `json:"delimiter"`
+	// Header overrides the header in the CSV file.
+	//
+	// This is optional and defaults to using the first line of the CSV file as the
+	// header.
+	Header string `json:"header"`
+	// FalsePositiveRate is the probability that a key that is not in the set is
+	// reported as being in the set. Lower rates use more memory. This is not used
+	// when the filter is loaded from a serialized filter.
+	//
+	// This is optional and defaults to 0.001 (0.1%).
+	FalsePositiveRate float64 `json:"false_positive_rate"`
+	// RefreshInterval is the amount of time between reloading the file. If the file
+	// cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      sync.Mutex
+	filter  atomic.Pointer[bloom.BloomFilter]
+	refresh *refresher
+}
+
+// Create a new Bloom filter KV store.
+func newKVBloomFilter(cfg config.Config) (*kvBloomFilter, error) {
+	var store kvBloomFilter
+	if err := iconfig.Decode(cfg.Settings, &store); err != nil {
+		return nil, err
+	}
+
+	if store.File == "" {
+		return nil, fmt.Errorf("kv: bloom_filter: file: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if store.Format == "" {
+		store.Format = "text"
+	}
+
+	switch store.Format {
+	case "text", "filter":
+	case "csv":
+		if store.Column == "" {
+			return nil, fmt.Errorf("kv: bloom_filter: column: %v", iconfig.ErrMissingRequiredOption)
+		}
+	default:
+		return nil, fmt.Errorf("kv: bloom_filter: format %s: %v", store.Format, iconfig.ErrInvalidOption)
+	}
+
+	if store.Delimiter == "" {
+		store.Delimiter = ","
+	}
+
+	if store.FalsePositiveRate == 0 {
+		store.FalsePositiveRate = 0.001
+	}
+
+	if store.FalsePositiveRate < 0 || store.FalsePositiveRate >= 1 {
+		return nil, fmt.Errorf("kv: bloom_filter: false_positive_rate: %v", errBloomFilterInvalidFalsePositiveRate)
+	}
+
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: bloom_filter: refresh_interval: %v", err)
+		}
+	}
+
+	return &store, nil
+}
+
+func (store *kvBloomFilter) String() string {
+	return toString(store)
+}
+
+// Get returns true if the key is probably in the set and false if the key is
+// definitely not in the set.
+func (store *kvBloomFilter) Get(ctx context.Context, key string) (interface{}, error) {
+	f := store.filter.Load()
+	if f == nil {
+		return false, nil
+	}
+
+	return f.TestString(key), nil
+}
+
+// Set is unused because this is a read-only store.
+func (store *kvBloomFilter) Set(ctx context.Context, key string, val interface{}) error {
+	return errSetNotSupported
+}
+
+// SetWithTTL is unused because this is a read-only store.
+func (store *kvBloomFilter) SetWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	return errSetNotSupported
+}
+
+// SetAddWithTTL is unused because this is a read-only store.
+func (store *kvBloomFilter) SetAddWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	return errSetNotSupported
+}
+
+// IsEnabled returns true if the store is ready for use.
+func (store *kvBloomFilter) IsEnabled() bool {
+	return store.filter.Load() != nil
+}
+
+// Setup creates the store by reading the file into a Bloom filter. If a refresh
+// interval is configured, then the file is periodically reloaded.
+func (store *kvBloomFilter) Setup(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary setup
+	if store.filter.Load() != nil {
+		return nil
+	}
+
+	if err := store.load(ctx); err != nil {
+		return err
+	}
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "bloom_filter", interval, store.load)
+	}
+
+	return nil
+}
+
+// Closes the store.
+func (store *kvBloomFilter) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary closing
+	if store.filter.Load() == nil {
+		return nil
+	}
+
+	if store.refresh != nil {
+		store.refresh.stop()
+		store.refresh = nil
+	}
+
+	store.filter.Store(nil)
+	return nil
+}
+
+// load reads the file into a new filter and replaces the current filter. If the
+// file cannot be read, then the current filter is not changed.
+func (store *kvBloomFilter) load(ctx context.Context) error {
+	path, err := file.Get(ctx, store.File)
+	defer os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("kv: bloom_filter: %v", err)
+	}
+
+	var f *bloom.BloomFilter
+	if store.Format == "filter" {
+		f, err = store.read(path)
+	} else {
+		f, err = store.build(path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("kv: bloom_filter: %v", err)
+	}
+
+	store.filter.Store(f)
+	return nil
+}
+
+// read loads a serialized filter.
+func (store *kvBloomFilter) read(path string) (*bloom.BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var filter bloom.BloomFilter
+	if _, err := filter.ReadFrom(bufio.NewReader(f)); err != nil {
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// build creates a filter from a text or CSV file. The file is read twice, first
+// to count the items and then to add them to a filter that is sized for the
+// false positive rate, so the items are never stored in memory.
+func (store *kvBloomFilter) build(path string) (*bloom.BloomFilter, error) {
+	var n uint
+	if err := store.scan(path, func(string) { n++ }); err != nil {
+		return nil, err
+	}
+
+	// A filter with no capacity cannot be created.
+	if n == 0 {
+		n = 1
+	}
+
+	filter := bloom.NewWithEstimates(n, store.FalsePositiveRate)
+	if err := store.scan(path, func(item string) { filter.AddString(item) }); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// scan calls fn for each item in a text or CSV file.
+func (store *kvBloomFilter) scan(path string, fn func(string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if store.Format == "text" {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fn(scanner.Text())
+		}
+
+		return scanner.Err()
+	}
+
+	r := bufio.NewReader(f)
+
+	var reader *csv.Reader
+	// the first line of the CSV file is replaced with header if it exists
+	if store.Header != "" {
+		if _, err := r.ReadString('\n'); err != nil {
+			return err
+		}
+
+		h := strings.NewReader(fmt.Sprintf("%s\n", store.Header))
+		reader = csv.NewReader(io.MultiReader(h, r))
+	} else {
+		reader = csv.NewReader(r)
+	}
+
+	// CSV reader only accepts runes for the comma / delimiter
+	d, _ := utf8.DecodeRune([]byte(store.Delimiter))
+	reader.Comma = d
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	col := -1
+	for i, h := range header {
+		if h == store.Column {
+			col = i
+			break
+		}
+	}
+
+	if col == -1 {
+		return errCSVFileColumnNotFound
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fn(row[col])
+	}
+}
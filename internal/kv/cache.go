@@ -0,0 +1,340 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/metrics"
+)
+
+// cacheMiss is stored in the cache when a value is missing (negative caching).
+type cacheMiss struct{}
+
+// MetricsGenerator is implemented by KV stores that generate metrics. Metrics are
+// usually generated when a control message is received.
+type MetricsGenerator interface {
+	GenerateMetrics(context.Context) error
+}
+
+// Cache is a read-through cache that is stored in memory. Values are evicted from
+// the cache using least recently used (LRU) eviction and time-to-live (TTL).
+//
+// This is safe for concurrent access.
+type Cache struct {
+	conf   iconfig.Cache
+	store  *kvMemory
+	ttl    time.Duration
+	negTTL time.Duration
+
+	metric metrics.Generator
+	hits   uint64
+	misses uint64
+}
+
+// NewCache returns a configured Cache.
+func NewCache(ctx context.Context, cfg iconfig.Cache) (*Cache, error) {
+	if cfg.TTL == "" {
+		cfg.TTL = "1m"
+	}
+
+	if cfg.NegativeTTL == "" {
+		cfg.NegativeTTL = "0s"
+	}
+
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("cache: ttl: %v", err)
+	}
+
+	negTTL, err := time.ParseDuration(cfg.NegativeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("cache: negative_ttl: %v", err)
+	}
+
+	c := &Cache{
+		conf:   cfg,
+		store:  &kvMemory{Capacity: cfg.Capacity},
+		ttl:    ttl,
+		negTTL: negTTL,
+	}
+
+	if err := c.store.Setup(ctx); err != nil {
+		return nil, fmt.Errorf("cache: %v", err)
+	}
+
+	if cfg.Metric.Destination.Type != "" {
+		m, err := metrics.New(ctx, cfg.Metric.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("cache: %v", err)
+		}
+
+		c.metric = m
+	}
+
+	return c, nil
+}
+
+// Get retrieves a value from the cache. If the value is not in the cache, then it is
+// retrieved by calling fn and the result is added to the cache. Errors returned by fn
+// are never cached.
+func (c *Cache) Get(ctx context.Context, key string, fn func(context.Context, string) (interface{}, error)) (interface{}, error) {
+	if v, ok := c.lookup(ctx, key); ok {
+		return v, nil
+	}
+
+	v, err := fn(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.add(ctx, key, v)
+	return v, nil
+}
+
+// lookup retrieves a value from the cache and records a hit or miss. Cached
+// missing values are returned as nil.
+func (c *Cache) lookup(ctx context.Context, key string) (interface{}, bool) {
+	v, _ := c.store.Get(ctx, key)
+	if v == nil {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	atomic.AddUint64(&c.hits, 1)
+	if _, ok := v.(cacheMiss); ok {
+		return nil, true
+	}
+
+	return v, true
+}
+
+// add adds a value to the cache. Missing (nil) values are only cached if a
+// negative TTL is configured.
+func (c *Cache) add(ctx context.Context, key string, v interface{}) {
+	if v == nil {
+		if c.negTTL > 0 {
+			_ = c.store.SetWithTTL(ctx, key, cacheMiss{}, cacheExpiration(c.negTTL))
+		}
+
+		return
+	}
+
+	if c.ttl > 0 {
+		_ = c.store.SetWithTTL(ctx, key, v, cacheExpiration(c.ttl))
+	}
+}
+
+// cacheExpiration returns the Unix time when a value that is added now with the
+// TTL expires. Partial seconds are rounded up so that values never expire early.
+func cacheExpiration(ttl time.Duration) int64 {
+	return int64(math.Ceil(float64(time.Now().Add(ttl).UnixNano()) / float64(time.Second)))
+}
+
+// Delete removes a value from the cache.
+func (c *Cache) Delete(key string) {
+	c.store.delete(key)
+}
+
+// GenerateMetrics sends the number of cache hits and misses to the configured metrics
+// destination and resets the counts. If no destination is configured, then this does
+// nothing.
+func (c *Cache) GenerateMetrics(ctx context.Context) error {
+	if c.metric == nil {
+		return nil
+	}
+
+	if err := c.metric.Generate(ctx, metrics.Data{
+		Name:       c.conf.Metric.Name + "Hits",
+		Value:      atomic.SwapUint64(&c.hits, 0),
+		Attributes: c.conf.Metric.Attributes,
+	}); err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+
+	if err := c.metric.Generate(ctx, metrics.Data{
+		Name:       c.conf.Metric.Name + "Misses",
+		Value:      atomic.SwapUint64(&c.misses, 0),
+		Attributes: c.conf.Metric.Attributes,
+	}); err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+
+	if err := metrics.Flush(ctx, c.metric); err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+
+	return nil
+}
+
+// kvCache is a read-through cache for any KV store. Values that are retrieved from
+// the store are cached in memory, and values that are set into the store are
+// removed from the cache.
+//
+// This is enabled by adding the "cache" setting to any KV store configuration.
+type kvCache struct {
+	Cache iconfig.Cache `json:"cache"`
+	Store Storer        `json:"store"`
+	cache *Cache
+}
+
+// Create a new cached KV store.
+func newKVCache(store Storer, cfg iconfig.Cache) (*kvCache, error) {
+	c, err := NewCache(context.TODO(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kv: %v", err)
+	}
+
+	return &kvCache{
+		Cache: cfg,
+		Store: store,
+		cache: c,
+	}, nil
+}
+
+// cacheConfig returns the cache configuration from a KV store configuration. If
+// no cache is configured, then this returns nil.
+func cacheConfig(cfg config.Config) (*iconfig.Cache, error) {
+	var conf struct {
+		Cache *iconfig.Cache `json:"cache"`
+	}
+
+	if err := iconfig.Decode(cfg.Settings, &conf); err != nil {
+		return nil, err
+	}
+
+	return conf.Cache, nil
+}
+
+func (store *kvCache) String() string {
+	return toString(store)
+}
+
+// Get retrieves a value from the cache or, if the value is not cached, from the store.
+func (store *kvCache) Get(ctx context.Context, key string) (interface{}, error) {
+	return store.cache.Get(ctx, key, store.Store.Get)
+}
+
+// Set adds a value to the store and removes it from the cache.
+func (store *kvCache) Set(ctx context.Context, key string, val interface{}) error {
+	defer store.cache.Delete(key)
+
+	return store.Store.Set(ctx, key, val)
+}
+
+// SetWithTTL adds a value to the store with a time-to-live (TTL) and removes
+// it from the cache.
+func (store *kvCache) SetWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	defer store.cache.Delete(key)
+
+	return store.Store.SetWithTTL(ctx, key, val, ttl)
+}
+
+// SetAddWithTTL adds a value to a set in the store and removes the set from
+// the cache.
+func (store *kvCache) SetAddWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	defer store.cache.Delete(key)
+
+	return store.Store.SetAddWithTTL(ctx, key, val, ttl)
+}
+
+// GetBatch retrieves values from the cache or, if the values are not cached, from
+// the store in a single batch.
+func (store *kvCache) GetBatch(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	out := make(map[string]interface{})
+
+	var misses []string
+	for _, k := range keys {
+		v, ok := store.cache.lookup(ctx, k)
+		if !ok {
+			misses = append(misses, k)
+			continue
+		}
+
+		if v != nil {
+			out[k] = v
+		}
+	}
+
+	if len(misses) == 0 {
+		return out, nil
+	}
+
+	res, err := GetBatch(ctx, store.Store, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range misses {
+		v := res[k]
+		store.cache.add(ctx, k, v)
+
+		if v != nil {
+			out[k] = v
+		}
+	}
+
+	return out, nil
+}
+
+// SetBatch adds items to the store and removes them from the cache.
+func (store *kvCache) SetBatch(ctx context.Context, items []Item) error {
+	defer func() {
+		for _, i := range items {
+			store.cache.Delete(i.Key)
+		}
+	}()
+
+	return SetBatch(ctx, store.Store, items)
+}
+
+// SetIfNotExistsWithTTL adds a value to the store if the key does not exist and
+// removes it from the cache.
+func (store *kvCache) SetIfNotExistsWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	s, ok := store.Store.(ConditionalStorer)
+	if !ok {
+		return errSetNotSupported
+	}
+
+	defer store.cache.Delete(key)
+
+	return s.SetIfNotExistsWithTTL(ctx, key, val, ttl)
+}
+
+// CompareAndSetWithTTL replaces a value in the store if the current value is equal
+// to the old value and removes it from the cache.
+func (store *kvCache) CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error {
+	s, ok := store.Store.(ConditionalStorer)
+	if !ok {
+		return errSetNotSupported
+	}
+
+	defer store.cache.Delete(key)
+
+	return s.CompareAndSetWithTTL(ctx, key, old, val, ttl)
+}
+
+// GenerateMetrics sends cache metrics to the configured metrics destination.
+func (store *kvCache) GenerateMetrics(ctx context.Context) error {
+	return store.cache.GenerateMetrics(ctx)
+}
+
+// IsEnabled returns true if the store is ready for use.
+func (store *kvCache) IsEnabled() bool {
+	return store.Store.IsEnabled()
+}
+
+// Setup creates the store.
+func (store *kvCache) Setup(ctx context.Context) error {
+	return store.Store.Setup(ctx)
+}
+
+// Closes the store.
+func (store *kvCache) Close() error {
+	return store.Store.Close()
+}
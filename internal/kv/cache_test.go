@@ -0,0 +1,199 @@
+package kv
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+// cacheTestLookup returns a lookup function that counts calls.
+func cacheTestLookup(val interface{}, calls *int) func(context.Context, string) (interface{}, error) {
+	return func(context.Context, string) (interface{}, error) {
+		*calls++
+		return val, nil
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		cfg      iconfig.Cache
+		val      interface{}
+		calls    int
+		hits     uint64
+		misses   uint64
+		expected interface{}
+	}{
+		{
+			"value",
+			iconfig.Cache{},
+			"b",
+			1,
+			2,
+			1,
+			"b",
+		},
+		{
+			"sub-second ttl",
+			iconfig.Cache{TTL: "500ms"},
+			"b",
+			1,
+			2,
+			1,
+			"b",
+		},
+		{
+			"missing value",
+			iconfig.Cache{},
+			nil,
+			3,
+			0,
+			3,
+			nil,
+		},
+		{
+			"negative caching",
+			iconfig.Cache{NegativeTTL: "1m"},
+			nil,
+			1,
+			2,
+			1,
+			nil,
+		},
+		{
+			"sub-second negative caching",
+			iconfig.Cache{NegativeTTL: "500ms"},
+			nil,
+			1,
+			2,
+			1,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewCache(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var calls int
+			for i := 0; i < 3; i++ {
+				v, err := c.Get(ctx, "a", cacheTestLookup(test.val, &calls))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if v != test.expected {
+					t.Errorf("expected %v, got %v", test.expected, v)
+				}
+			}
+
+			if calls != test.calls {
+				t.Errorf("expected %d calls, got %d", test.calls, calls)
+			}
+
+			if hits := atomic.LoadUint64(&c.hits); hits != test.hits {
+				t.Errorf("expected %d hits, got %d", test.hits, hits)
+			}
+
+			if misses := atomic.LoadUint64(&c.misses); misses != test.misses {
+				t.Errorf("expected %d misses, got %d", test.misses, misses)
+			}
+		})
+	}
+}
+
+func TestCacheExpiration(t *testing.T) {
+	ctx := context.TODO()
+
+	c, err := NewCache(ctx, iconfig.Cache{TTL: "1s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	if _, err := c.Get(ctx, "a", cacheTestLookup("b", &calls)); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if _, err := c.Get(ctx, "a", cacheTestLookup("b", &calls)); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestKVCacheInvalidation(t *testing.T) {
+	ctx := context.TODO()
+
+	mem := &kvMemory{Capacity: 10}
+	if err := mem.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := newKVCache(mem, iconfig.Cache{TTL: "1h", NegativeTTL: "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		set  func() error
+	}{
+		{"set", func() error { return store.Set(ctx, "a", "c") }},
+		{"set_with_ttl", func() error { return store.SetWithTTL(ctx, "a", "c", time.Now().Add(time.Hour).Unix()) }},
+		{"set_batch", func() error { return store.SetBatch(ctx, []Item{{Key: "a", Value: "c"}}) }},
+		{"set_if_not_exists", func() error { return store.SetIfNotExistsWithTTL(ctx, "a", "c", 0) }},
+		{"compare_and_set", func() error { return store.CompareAndSetWithTTL(ctx, "a", "b", "c", 0) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.name != "set_if_not_exists" {
+				if err := mem.Set(ctx, "a", "b"); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				mem.delete("a")
+			}
+
+			// The value is cached, including missing values.
+			if _, err := store.Get(ctx, "a"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := test.set(); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := store.Get(ctx, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != "c" {
+				t.Errorf("expected c, got %v", v)
+			}
+
+			res, err := store.GetBatch(ctx, []string{"a"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res["a"] != "c" {
+				t.Errorf("expected c, got %v", res["a"])
+			}
+		})
+	}
+}
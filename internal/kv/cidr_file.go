@@ -0,0 +1,357 @@
+package kv
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brexhq/substation/v2/config"
+
+	"github.com/brexhq/substation/v2/internal/cidr"
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+var (
+	// errCIDRFileKeyMustBeAddr is returned when the key used in a Get call is not
+	// a valid IP address.
+	errCIDRFileKeyMustBeAddr = fmt.Errorf("key must be IP address")
+	// errCIDRFileInvalidFormat is returned when the file format is not supported.
+	errCIDRFileInvalidFormat = fmt.Errorf("invalid format")
+)
+
+// kvCIDRFile is a read-only key-value store that is derived from a file containing
+// CIDR ranges and stored in memory.
+//
+// Ranges are stored in a radix tree and keys are retrieved using longest prefix
+// matching, so the most specific range that contains an IP address is returned.
+// IPv4 and IPv6 ranges are supported, and IP addresses in the file are treated as
+// single host ranges (/32 or /128).
+//
+// The file can be either CSV or JSON. CSV files are read the same as the csv_file
+// store, where the column contains the range and the remaining values from the row
+// become the value. For example, if the file contains this data:
+//
+//	cidr,owner,env
+//	10.0.0.0/8,infra,prod
+//	10.1.0.0/16,security,dev
+//
+// By setting the column to "cidr", the key "10.1.2.3" returns this value:
+//
+//	{"env":"dev","owner":"security"}
+//
+// JSON files contain either an object where each key is a range and the value is
+// any JSON value:
+//
+//	{"10.0.0.0/8":{"owner":"infra"},"2001:db8::/32":{"owner":"security"}}
+//
+// Or an array of objects, where the column contains the range and the remaining
+// fields from the object become the value:
+//
+//	[{"cidr":"10.0.0.0/8","owner":"infra"},{"cidr":"2001:db8::/32","owner":"security"}]
+type kvCIDRFile struct {
+	// File contains the location of the file. This can be either a path on local
+	// disk, an HTTP(S) URL, or an AWS S3 URL.
+	File string `json:"file"`
+	// Format is the format of the file. Must be one of:
+	//
+	// - csv
+	//
+	// - json
+	//
+	// This is optional and defaults to csv.
+	Format string `json:"format"`
+	// Column determines which values from the file are loaded into the store as
+	// ranges. This is required for CSV files and JSON files that contain arrays.
+	Column string `json:"column"`
+	// Delimiter is the delimiting character (e.g., comma, tab) that separates values
+	// in rows in the CSV file.
+	//
+	// This is optional and defaults to comma (",").
+	Delimiter string `json:"delimiter"`
+	// Header overrides the header in the CSV file.
+	//
+	// This is optional and defaults to using the first line of the CSV file as the
+	// header.
+	Header string `json:"header"`
+	// RefreshInterval is the amount of time between reloading the file. If the file
+	// cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      sync.Mutex
+	tree    atomic.Pointer[cidr.Tree]
+	refresh *refresher
+}
+
+// Create a new CIDR file KV store.
+func newKVCIDRFile(cfg config.Config) (*kvCIDRFile, error) {
+	var store kvCIDRFile
+	if err := iconfig.Decode(cfg.Settings, &store); err != nil {
+		return nil, err
+	}
+
+	if store.File == "" {
+		return nil, fmt.Errorf("kv: cidr_file: file: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if store.Format == "" {
+		store.Format = "csv"
+	}
+
+	switch store.Format {
+	case "csv":
+		if store.Column == "" {
+			return nil, fmt.Errorf("kv: cidr_file: column: %v", iconfig.ErrMissingRequiredOption)
+		}
+	case "json":
+	default:
+		return nil, fmt.Errorf("kv: cidr_file: format %s: %v", store.Format, errCIDRFileInvalidFormat)
+	}
+
+	if store.Delimiter == "" {
+		store.Delimiter = ","
+	}
+
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: cidr_file: refresh_interval: %v", err)
+		}
+	}
+
+	return &store, nil
+}
+
+func (store *kvCIDRFile) String() string {
+	return toString(store)
+}
+
+// Get retrieves the value of the most specific range that contains the IP address.
+func (store *kvCIDRFile) Get(ctx context.Context, key string) (interface{}, error) {
+	addr, err := netip.ParseAddr(key)
+	if err != nil {
+		// does not include the key that caused the error to avoid leaking
+		// private data. this should be wrapped by the caller, which can
+		// provide more information about what caused the error.
+		return nil, fmt.Errorf("kv: cidr_file: %v", errCIDRFileKeyMustBeAddr)
+	}
+
+	tree := store.tree.Load()
+	if tree == nil {
+		return nil, nil
+	}
+
+	v, _ := tree.Lookup(addr)
+	return v, nil
+}
+
+// Set is unused because this is a read-only store.
+func (store *kvCIDRFile) Set(ctx context.Context, key string, val interface{}) error {
+	return errSetNotSupported
+}
+
+// SetWithTTL is unused because this is a read-only store.
+func (store *kvCIDRFile) SetWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	return errSetNotSupported
+}
+
+// SetAddWithTTL is unused because this is a read-only store.
+func (store *kvCIDRFile) SetAddWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	return errSetNotSupported
+}
+
+// IsEnabled returns true if the store is ready for use.
+func (store *kvCIDRFile) IsEnabled() bool {
+	return store.tree.Load() != nil
+}
+
+// Setup creates the store by reading the file into memory. If a refresh interval
+// is configured, then the file is periodically reloaded.
+func (store *kvCIDRFile) Setup(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary setup
+	if store.tree.Load() != nil {
+		return nil
+	}
+
+	if err := store.load(ctx); err != nil {
+		return err
+	}
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "cidr_file", interval, store.load)
+	}
+
+	return nil
+}
+
+// Closes the store.
+func (store *kvCIDRFile) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// avoids unnecessary closing
+	if store.tree.Load() == nil {
+		return nil
+	}
+
+	if store.refresh != nil {
+		store.refresh.stop()
+		store.refresh = nil
+	}
+
+	store.tree.Store(nil)
+	return nil
+}
+
+// load reads the file into a new tree and replaces the current tree. If the file
+// cannot be read, then the current tree is not changed.
+func (store *kvCIDRFile) load(ctx context.Context) error {
+	path, err := file.Get(ctx, store.File)
+	defer os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("kv: cidr_file: %v", err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("kv: cidr_file: %v", err)
+	}
+
+	var tree *cidr.Tree
+	switch store.Format {
+	case "csv":
+		tree, err = store.loadCSV(buf)
+	case "json":
+		tree, err = store.loadJSON(buf)
+	}
+
+	if err != nil {
+		return fmt.Errorf("kv: cidr_file: %v", err)
+	}
+
+	store.tree.Store(tree)
+	return nil
+}
+
+func (store *kvCIDRFile) loadCSV(buf []byte) (*cidr.Tree, error) {
+	data := string(buf)
+
+	// the first line of the CSV file is replaced with header if it exists
+	if store.Header != "" {
+		_, rest, _ := strings.Cut(data, "\n")
+		data = store.Header + "\n" + rest
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+
+	// CSV reader only accepts runes for the comma / delimiter
+	r, _ := utf8.DecodeRune([]byte(store.Delimiter))
+	reader.Comma = r
+
+	// any errors in the CSV file are raised here
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return &cidr.Tree{}, nil
+	}
+
+	header := rows[0]
+	col := -1
+	for i, h := range header {
+		if h == store.Column {
+			col = i
+			break
+		}
+	}
+
+	if col == -1 {
+		return nil, errCSVFileColumnNotFound
+	}
+
+	tree := &cidr.Tree{}
+	for _, row := range rows[1:] {
+		// the value is the row with the column's value removed
+		val := make(map[string]interface{})
+		for i := 0; i < len(row); i++ {
+			if i == col {
+				continue
+			}
+
+			val[header[i]] = row[i]
+		}
+
+		if err := tree.InsertString(row[col], val); err != nil {
+			return nil, err
+		}
+	}
+
+	return tree, nil
+}
+
+func (store *kvCIDRFile) loadJSON(buf []byte) (*cidr.Tree, error) {
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, fmt.Errorf("%v: %v", errJSONFileInvalid, err)
+	}
+
+	tree := &cidr.Tree{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if err := tree.InsertString(k, val); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		if store.Column == "" {
+			return nil, fmt.Errorf("column: %v", iconfig.ErrMissingRequiredOption)
+		}
+
+		for _, elem := range v {
+			obj, ok := elem.(map[string]interface{})
+			if !ok {
+				return nil, errJSONFileInvalid
+			}
+
+			r, ok := obj[store.Column].(string)
+			if !ok {
+				return nil, errCSVFileColumnNotFound
+			}
+
+			// the value is the object with the column's value removed
+			val := make(map[string]interface{})
+			for k, v := range obj {
+				if k == store.Column {
+					continue
+				}
+
+				val[k] = v
+			}
+
+			if err := tree.InsertString(r, val); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errJSONFileInvalid
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,183 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// conditionalTestStore is a store that supports conditional writes.
+type conditionalTestStore interface {
+	Storer
+	ConditionalStorer
+}
+
+// testKVConditional tests the conditional writes of a store. newStore returns an
+// empty store and a function that expires every item that has a TTL in the past.
+func testKVConditional(t *testing.T, newStore func(*testing.T) (conditionalTestStore, func())) {
+	ctx := context.TODO()
+
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Second).Unix()
+
+	tests := []struct {
+		name string
+		// setup is applied to the store before the test.
+		setup    func(conditionalTestStore) error
+		test     func(conditionalTestStore) error
+		err      error
+		expected interface{}
+	}{
+		{
+			"set_if_not_exists",
+			func(s conditionalTestStore) error { return nil },
+			func(s conditionalTestStore) error { return s.SetIfNotExistsWithTTL(ctx, "a", "b", future) },
+			nil,
+			"b",
+		},
+		{
+			"set_if_not_exists exists",
+			func(s conditionalTestStore) error { return s.Set(ctx, "a", "b") },
+			func(s conditionalTestStore) error { return s.SetIfNotExistsWithTTL(ctx, "a", "c", future) },
+			ErrConditionFailed,
+			"b",
+		},
+		{
+			"set_if_not_exists expired",
+			func(s conditionalTestStore) error { return s.SetWithTTL(ctx, "a", "b", past) },
+			func(s conditionalTestStore) error { return s.SetIfNotExistsWithTTL(ctx, "a", "c", future) },
+			nil,
+			"c",
+		},
+		{
+			"compare_and_set",
+			func(s conditionalTestStore) error { return s.Set(ctx, "a", "b") },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", "b", "c", future) },
+			nil,
+			"c",
+		},
+		{
+			"compare_and_set not equal",
+			func(s conditionalTestStore) error { return s.Set(ctx, "a", "b") },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", "x", "c", future) },
+			ErrConditionFailed,
+			"b",
+		},
+		{
+			"compare_and_set missing",
+			func(s conditionalTestStore) error { return nil },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", "b", "c", future) },
+			ErrConditionFailed,
+			nil,
+		},
+		{
+			"compare_and_set nil missing",
+			func(s conditionalTestStore) error { return nil },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", nil, "c", future) },
+			ErrConditionFailed,
+			nil,
+		},
+		{
+			"compare_and_set expired",
+			func(s conditionalTestStore) error { return s.SetWithTTL(ctx, "a", "b", past) },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", "b", "c", future) },
+			ErrConditionFailed,
+			nil,
+		},
+		{
+			"compare_and_set without ttl",
+			func(s conditionalTestStore) error { return s.Set(ctx, "a", "b") },
+			func(s conditionalTestStore) error { return s.CompareAndSetWithTTL(ctx, "a", "b", "c", 0) },
+			nil,
+			"c",
+		},
+		{
+			"compare_and_set object",
+			func(s conditionalTestStore) error { return s.Set(ctx, "a", map[string]interface{}{"b": "c"}) },
+			func(s conditionalTestStore) error {
+				return s.CompareAndSetWithTTL(ctx, "a", map[string]interface{}{"b": "c"}, "d", future)
+			},
+			nil,
+			"d",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, expire := newStore(t)
+
+			if err := test.setup(store); err != nil {
+				t.Fatal(err)
+			}
+			expire()
+
+			if err := test.test(store); !errors.Is(err, test.err) {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+
+			v, err := store.Get(ctx, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestKVMemoryConditional(t *testing.T) {
+	testKVConditional(t, func(t *testing.T) (conditionalTestStore, func()) {
+		store := &kvMemory{}
+		if err := store.Setup(context.TODO()); err != nil {
+			t.Fatal(err)
+		}
+
+		return store, func() {}
+	})
+}
+
+func TestKVBBoltConditional(t *testing.T) {
+	testKVConditional(t, func(t *testing.T) (conditionalTestStore, func()) {
+		store := newTestKVBBolt(t, filepath.Join(t.TempDir(), "kv.db"))
+		t.Cleanup(func() { _ = store.Close() })
+
+		return store, func() {}
+	})
+}
+
+func TestKVRedisConditional(t *testing.T) {
+	testKVConditional(t, func(t *testing.T) (conditionalTestStore, func()) {
+		store, srv := newTestKVRedis(t)
+
+		// miniredis only expires keys when time is advanced.
+		return store, func() { srv.FastForward(time.Second) }
+	})
+}
+
+func TestIsConditional(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    Storer
+		expected bool
+	}{
+		{"memory", &kvMemory{}, true},
+		{"bbolt", &kvBBolt{}, true},
+		{"redis", &kvRedis{}, true},
+		{"text_file", &kvTextFile{}, false},
+		{"cached memory", &kvCache{Store: &kvMemory{}}, true},
+		{"cached text_file", &kvCache{Store: &kvTextFile{}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if IsConditional(test.store) != test.expected {
+				t.Errorf("expected %v", test.expected)
+			}
+		})
+	}
+}
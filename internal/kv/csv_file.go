@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/brexhq/substation/v2/config"
@@ -58,8 +59,15 @@ type kvCSVFile struct {
 	// This is optional and defaults to using the first line of the CSV file as the
 	// header.
 	Header string `json:"header"`
-	mu     sync.Mutex
-	items  map[string]map[string]interface{}
+	// RefreshInterval is the amount of time between reloading the CSV file. If the
+	// file cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      sync.Mutex
+	items   map[string]map[string]interface{}
+	refresh *refresher
 }
 
 // Create a new CSV file KV store.
@@ -73,6 +81,16 @@ func newKVCSVFile(cfg config.Config) (*kvCSVFile, error) {
 		return nil, fmt.Errorf("kv: csv: options %+v: %v", &store, iconfig.ErrMissingRequiredOption)
 	}
 
+	if store.Delimiter == "" {
+		store.Delimiter = ","
+	}
+
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: csv_file: refresh_interval: %v", err)
+		}
+	}
+
 	return &store, nil
 }
 
@@ -125,17 +143,49 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 		return nil
 	}
 
-	store.items = make(map[string]map[string]interface{})
+	items, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+	store.items = items
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "csv_file", interval, store.reload)
+	}
+
+	return nil
+}
+
+// reload reads the CSV file and replaces the items in the store. If the file cannot
+// be read, then the items are not changed.
+func (store *kvCSVFile) reload(ctx context.Context) error {
+	items, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.items = items
+	return nil
+}
+
+// load reads the CSV file into a new map.
+func (store *kvCSVFile) load(ctx context.Context) (map[string]map[string]interface{}, error) {
+	items := make(map[string]map[string]interface{})
 
 	path, err := file.Get(ctx, store.File)
 	defer os.Remove(path)
 	if err != nil {
-		return fmt.Errorf("kv: csv_file: %v", err)
+		return nil, fmt.Errorf("kv: csv_file: %v", err)
 	}
 
 	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("kv: csv_file: %v", err)
+		return nil, fmt.Errorf("kv: csv_file: %v", err)
 	}
 
 	defer f.Close()
@@ -145,10 +195,10 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 	if store.Header != "" {
 		buf, err := bufio.NewReader(f).ReadString('\n')
 		if err != nil {
-			return fmt.Errorf("kv: csv_file: %v", err)
+			return nil, fmt.Errorf("kv: csv_file: %v", err)
 		}
 		if _, err = f.Seek(int64(len(buf)), io.SeekStart); err != nil {
-			return fmt.Errorf("kv: csv_file: %v", err)
+			return nil, fmt.Errorf("kv: csv_file: %v", err)
 		}
 
 		h := strings.NewReader(fmt.Sprintf("%s\n", store.Header))
@@ -157,10 +207,6 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 		reader = csv.NewReader(f)
 	}
 
-	if store.Delimiter == "" {
-		store.Delimiter = ","
-	}
-
 	// CSV reader only accepts runes for the comma / delimiter
 	r, _ := utf8.DecodeRune([]byte(store.Delimiter))
 	reader.Comma = r
@@ -168,7 +214,7 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 	// any errors in the CSV file are raised here
 	rows, err := reader.ReadAll()
 	if err != nil {
-		return fmt.Errorf("kv: csv_file: %v", err)
+		return nil, fmt.Errorf("kv: csv_file: %v", err)
 	}
 
 	var header []string
@@ -189,7 +235,7 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 			}
 
 			if key == "" {
-				return fmt.Errorf("kv: csv_file: %v", errCSVFileColumnNotFound)
+				return nil, fmt.Errorf("kv: csv_file: %v", errCSVFileColumnNotFound)
 			}
 
 			// the KV store value is the row with the column's value removed
@@ -202,15 +248,26 @@ func (store *kvCSVFile) Setup(ctx context.Context) error {
 				val[header[i]] = row[i]
 			}
 
-			store.items[key] = val
+			items[key] = val
 		}
 	}
 
-	return nil
+	return items, nil
 }
 
 // Closes the store.
 func (store *kvCSVFile) Close() error {
+	// The refresher is stopped before the lock is held because
+	// it may be waiting on the lock to replace the items.
+	store.mu.Lock()
+	r := store.refresh
+	store.refresh = nil
+	store.mu.Unlock()
+
+	if r != nil {
+		r.stop()
+	}
+
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
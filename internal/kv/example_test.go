@@ -3,9 +3,12 @@ package kv_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/brexhq/substation/v2/config"
+	iconfig "github.com/brexhq/substation/v2/internal/config"
 	"github.com/brexhq/substation/v2/internal/kv"
 )
 
@@ -97,3 +100,134 @@ func Example_memoryWithTTL() {
 	// Output: <nil>
 	fmt.Println(item)
 }
+
+func Example_bbolt() {
+	ctx := context.TODO()
+
+	// temp dir is used to store the database file and must be removed after the example completes
+	dir, err := os.MkdirTemp("", "substation")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// create KV config
+	cfg := config.Config{
+		Type: "bbolt",
+		Settings: map[string]interface{}{
+			"file": filepath.Join(dir, "kv.db"),
+		},
+	}
+
+	// get KV store using factory method
+	kvStore, err := kv.Get(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	// setup and defer closing KV store
+	if err := kvStore.Setup(ctx); err != nil {
+		panic(err)
+	}
+	defer kvStore.Close()
+
+	// add values to a set in the store, duplicate values are ignored
+	for _, v := range []string{"bar", "baz", "bar"} {
+		if err := kvStore.SetAddWithTTL(ctx, "foo", v, 0); err != nil {
+			panic(err)
+		}
+	}
+
+	// retrieve a value from the store
+	item, err := kvStore.Get(ctx, "foo")
+	if err != nil {
+		panic(err)
+	}
+
+	// Output: [bar baz]
+	fmt.Println(item)
+}
+
+func Example_cidrFile() {
+	ctx := context.TODO()
+
+	dir, err := os.MkdirTemp("", "substation")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// create a CSV file that maps CIDR ranges to owners
+	f := filepath.Join(dir, "ranges.csv")
+	data := "cidr,owner\n10.0.0.0/8,infra\n10.1.0.0/16,security\n2001:db8::/32,network\n"
+	if err := os.WriteFile(f, []byte(data), 0o600); err != nil {
+		panic(err)
+	}
+
+	cfg := config.Config{
+		Type: "cidr_file",
+		Settings: map[string]interface{}{
+			"file":   f,
+			"column": "cidr",
+		},
+	}
+
+	kvStore, err := kv.New(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := kvStore.Setup(ctx); err != nil {
+		panic(err)
+	}
+	defer kvStore.Close()
+
+	// the most specific range that contains the address is returned
+	for _, ip := range []string{"10.1.2.3", "10.2.3.4", "2001:db8::1", "192.168.1.1"} {
+		val, err := kvStore.Get(ctx, ip)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(ip, val)
+	}
+
+	// Output:
+	// 10.1.2.3 map[owner:security]
+	// 10.2.3.4 map[owner:infra]
+	// 2001:db8::1 map[owner:network]
+	// 192.168.1.1 <nil>
+}
+
+func ExampleCache() {
+	ctx := context.TODO()
+
+	cache, err := kv.NewCache(ctx, iconfig.Cache{
+		Capacity: 10,
+		TTL:      "1m",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// fn is only called when the value is not in the cache.
+	calls := 0
+	fn := func(ctx context.Context, key string) (interface{}, error) {
+		calls++
+		return fmt.Sprintf("value of %s", key), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get(ctx, "foo", fn); err != nil {
+			panic(err)
+		}
+	}
+
+	v, err := cache.Get(ctx, "foo", fn)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(v, calls)
+	// Output: value of foo 1
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tidwall/gjson"
 
@@ -28,9 +29,15 @@ type kvJSONFile struct {
 	// IsLines indicates that the file is a JSON Lines file. The first non-null value
 	// is returned when a key is found.
 	IsLines bool `json:"is_lines"`
-
-	mu     *sync.Mutex
-	object []byte
+	// RefreshInterval is the amount of time between reloading the JSON file. If the
+	// file cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      *sync.Mutex
+	object  []byte
+	refresh *refresher
 }
 
 // Create a new JSON file KV store.
@@ -45,6 +52,12 @@ func newKVJSONFile(cfg config.Config) (*kvJSONFile, error) {
 		return nil, fmt.Errorf("kv: json: options %+v: %v", &store, iconfig.ErrMissingRequiredOption)
 	}
 
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: json_file: refresh_interval: %v", err)
+		}
+	}
+
 	return &store, nil
 }
 
@@ -113,27 +126,69 @@ func (store *kvJSONFile) Setup(ctx context.Context) error {
 		return nil
 	}
 
+	object, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+	store.object = object
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "json_file", interval, store.reload)
+	}
+
+	return nil
+}
+
+// reload reads the JSON file and replaces the object in the store. If the file
+// cannot be read, then the object is not changed.
+func (store *kvJSONFile) reload(ctx context.Context) error {
+	object, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.object = object
+	return nil
+}
+
+// load reads and validates the JSON file.
+func (store *kvJSONFile) load(ctx context.Context) ([]byte, error) {
 	path, err := file.Get(ctx, store.File)
 	defer os.Remove(path)
 	if err != nil {
-		return fmt.Errorf("kv: json_file: %v", err)
+		return nil, fmt.Errorf("kv: json_file: %v", err)
 	}
 
 	buf, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("kv: json_file: %v", err)
+		return nil, fmt.Errorf("kv: json_file: %v", err)
 	}
 
 	if !json.Valid(buf) {
-		return fmt.Errorf("kv: json_file: %v", errJSONFileInvalid)
+		return nil, fmt.Errorf("kv: json_file: %v", errJSONFileInvalid)
 	}
 
-	store.object = buf
-	return nil
+	return buf, nil
 }
 
 // Closes the store.
 func (store *kvJSONFile) Close() error {
+	// The refresher is stopped before the lock is held because
+	// it may be waiting on the lock to replace the object.
+	store.mu.Lock()
+	r := store.refresh
+	store.refresh = nil
+	store.mu.Unlock()
+
+	if r != nil {
+		r.stop()
+	}
+
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
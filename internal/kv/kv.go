@@ -9,6 +9,7 @@ import (
 	"github.com/brexhq/substation/v2/config"
 
 	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/secrets"
 )
 
 var (
@@ -19,6 +20,9 @@ var (
 	errSetNotSupported = fmt.Errorf("set not supported")
 	// ErrNoLock is returned when a lock cannot be acquired.
 	ErrNoLock = fmt.Errorf("unable to acquire lock")
+	// ErrConditionFailed is returned when a conditional write is not completed
+	// because the item in the store does not match the condition.
+	ErrConditionFailed = fmt.Errorf("condition failed")
 )
 
 // Storer provides tools for getting values from and putting values into key-value stores.
@@ -32,10 +36,103 @@ type Storer interface {
 	IsEnabled() bool
 }
 
+// Item is a value that is added to a store in a batch.
+type Item struct {
+	Key   string
+	Value interface{}
+	// TTL is the Unix time when the item expires. A zero value indicates
+	// that the item does not expire.
+	TTL int64
+}
+
+// BatchStorer is implemented by KV stores that can get and set multiple values
+// in a single call.
+type BatchStorer interface {
+	// GetBatch retrieves values from the store. Keys that are not in the store
+	// are not included in the result.
+	GetBatch(context.Context, []string) (map[string]interface{}, error)
+	// SetBatch adds items to the store.
+	SetBatch(context.Context, []Item) error
+}
+
+// ConditionalStorer is implemented by KV stores that support conditional writes.
+// If the condition is not met, then ErrConditionFailed is returned.
+type ConditionalStorer interface {
+	// SetIfNotExistsWithTTL adds a value to the store only if the key does not
+	// exist or has expired.
+	SetIfNotExistsWithTTL(context.Context, string, interface{}, int64) error
+	// CompareAndSetWithTTL replaces a value in the store only if the key exists, has
+	// not expired, and the current value is equal to the old value. A nil old value
+	// matches a key that exists with no value; it never matches a missing key.
+	CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error
+}
+
+// IsConditional returns true if the store supports conditional writes. Stores that
+// are wrapped with a cache only support conditional writes if the wrapped store
+// supports them.
+func IsConditional(store Storer) bool {
+	if c, ok := store.(*kvCache); ok {
+		store = c.Store
+	}
+
+	_, ok := store.(ConditionalStorer)
+	return ok
+}
+
+// GetBatch retrieves multiple values from a store. If the store does not implement
+// BatchStorer, then each value is retrieved individually.
+func GetBatch(ctx context.Context, store Storer, keys []string) (map[string]interface{}, error) {
+	if b, ok := store.(BatchStorer); ok {
+		return b.GetBatch(ctx, keys)
+	}
+
+	out := make(map[string]interface{})
+	for _, k := range keys {
+		if _, ok := out[k]; ok {
+			continue
+		}
+
+		v, err := store.Get(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+
+		if v != nil {
+			out[k] = v
+		}
+	}
+
+	return out, nil
+}
+
+// SetBatch adds multiple items to a store. If the store does not implement
+// BatchStorer, then each item is added individually.
+func SetBatch(ctx context.Context, store Storer, items []Item) error {
+	if b, ok := store.(BatchStorer); ok {
+		return b.SetBatch(ctx, items)
+	}
+
+	for _, i := range items {
+		if i.TTL == 0 {
+			if err := store.Set(ctx, i.Key, i.Value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := store.SetWithTTL(ctx, i.Key, i.Value, i.TTL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // required to support Stringer interface
 func toString(s Storer) string {
 	b, _ := json.Marshal(s)
-	return string(b)
+	return secrets.Redact(string(b))
 }
 
 // Get returns a pointer to a Storer that is stored as a package level global variable.
@@ -62,11 +159,41 @@ func Get(cfg config.Config) (Storer, error) {
 	return m[sig], nil
 }
 
-// New returns a Storer.
+// New returns a Storer. If the configuration contains a cache, then the Storer
+// is wrapped with a read-through cache.
 func New(cfg config.Config) (Storer, error) {
+	store, err := newStorer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cacheConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kv_store: %s: %v", cfg.Type, err)
+	}
+
+	if c == nil {
+		return store, nil
+	}
+
+	cached, err := newKVCache(store, *c)
+	if err != nil {
+		return nil, err
+	}
+
+	return cached, nil
+}
+
+func newStorer(cfg config.Config) (Storer, error) {
 	switch t := cfg.Type; t {
 	case "aws_dynamodb":
 		return newKVAWSDynamoDB(cfg)
+	case "bbolt":
+		return newKVBBolt(cfg)
+	case "bloom_filter":
+		return newKVBloomFilter(cfg)
+	case "cidr_file":
+		return newKVCIDRFile(cfg)
 	case "csv_file":
 		return newKVCSVFile(cfg)
 	case "json_file":
@@ -75,6 +202,8 @@ func New(cfg config.Config) (Storer, error) {
 		return newKVMemory(cfg)
 	case "mmdb":
 		return newKVMMDB(cfg)
+	case "redis":
+		return newKVRedis(cfg)
 	case "text_file":
 		return newKVTextFile(cfg)
 	default:
@@ -117,8 +246,12 @@ func NewLocker(cfg config.Config) (Locker, error) {
 	switch t := cfg.Type; t {
 	case "aws_dynamodb":
 		return newKVAWSDynamoDB(cfg)
+	case "bbolt":
+		return newKVBBolt(cfg)
 	case "memory":
 		return newKVMemory(cfg)
+	case "redis":
+		return newKVRedis(cfg)
 	default:
 		return nil, fmt.Errorf("kv_store locker: %s: %v", t, iconfig.ErrInvalidFactoryInput)
 	}
@@ -3,6 +3,7 @@ package kv
 import (
 	"container/list"
 	"context"
+	"reflect"
 	"slices"
 	"sync"
 	"time"
@@ -86,6 +87,59 @@ func (store *kvMemory) SetWithTTL(ctx context.Context, key string, val interface
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
+	store.set(key, val, ttl)
+	return nil
+}
+
+// SetIfNotExistsWithTTL adds a value to the store with a time-to-live (TTL) if the
+// key does not exist or has expired. If the key exists, then this returns
+// ErrConditionFailed.
+func (store *kvMemory) SetIfNotExistsWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.current(key); ok {
+		return ErrConditionFailed
+	}
+
+	store.set(key, val, ttl)
+	return nil
+}
+
+// CompareAndSetWithTTL replaces a value in the store with a time-to-live (TTL) if
+// the current value is deeply equal to the old value. If the values are not equal
+// or the key does not exist, then this returns ErrConditionFailed.
+func (store *kvMemory) CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	cur, ok := store.current(key)
+	if !ok || !reflect.DeepEqual(cur, old) {
+		return ErrConditionFailed
+	}
+
+	store.set(key, val, ttl)
+	return nil
+}
+
+// current returns the value of a key if it exists and has not expired. The caller
+// must hold the lock.
+func (store *kvMemory) current(key string) (interface{}, bool) {
+	node, ok := store.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	elem := node.Value.(kvMemoryElement)
+	if elem.ttl != 0 && elem.ttl <= time.Now().Unix() {
+		return nil, false
+	}
+
+	return elem.value, true
+}
+
+// set adds a value to the store. The caller must hold the lock.
+func (store *kvMemory) set(key string, val interface{}, ttl int64) {
 	value := kvMemoryElement{key, val, ttl}
 
 	if node, ok := store.items[key]; ok {
@@ -93,7 +147,7 @@ func (store *kvMemory) SetWithTTL(ctx context.Context, key string, val interface
 		store.lru.MoveToFront(node)
 		node.Value = value
 
-		return nil
+		return
 	}
 
 	store.lru.PushFront(value)
@@ -105,8 +159,6 @@ func (store *kvMemory) SetWithTTL(ctx context.Context, key string, val interface
 		store.lru.Remove(node)
 		delete(store.items, node.Value.(kvMemoryElement).key)
 	}
-
-	return nil
 }
 
 // Lock adds an item to the store if it does not already exist. If the item already exists
@@ -183,6 +235,17 @@ func (store *kvMemory) Unlock(ctx context.Context, key string) error {
 	return nil
 }
 
+// delete removes an item from the store.
+func (store *kvMemory) delete(key string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if node, ok := store.items[key]; ok {
+		store.lru.Remove(node)
+		delete(store.items, key)
+	}
+}
+
 // IsEnabled returns true if the store is ready for use.
 func (store *kvMemory) IsEnabled() bool {
 	store.mu.Lock()
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/oschwald/maxminddb-golang"
 
@@ -30,9 +31,16 @@ var errMMDBKeyMustBeAddr = fmt.Errorf("key must be IP address")
 type kvMMDB struct {
 	// File contains the location of the MMDB file. This can be either a path on local
 	// disk, an HTTP(S) URL, or an AWS S3 URL.
-	File   string `json:"file"`
-	mu     sync.RWMutex
-	reader *maxminddb.Reader
+	File string `json:"file"`
+	// RefreshInterval is the amount of time between reloading the MMDB file. If the
+	// file cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	refresh *refresher
 }
 
 // Create a new MMDB KV store.
@@ -46,6 +54,12 @@ func newKVMMDB(cfg config.Config) (*kvMMDB, error) {
 		return nil, fmt.Errorf("kv: mmdb: options %+v: %v", &store, iconfig.ErrMissingRequiredOption)
 	}
 
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: mmdb: refresh_interval: %v", err)
+		}
+	}
+
 	return &store, nil
 }
 
@@ -107,23 +121,74 @@ func (store *kvMMDB) Setup(ctx context.Context) error {
 		return nil
 	}
 
+	db, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+	store.reader = db
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "mmdb", interval, store.reload)
+	}
+
+	return nil
+}
+
+// reload reads the MMDB file and replaces the reader in the store. If the file
+// cannot be read, then the reader is not changed.
+func (store *kvMMDB) reload(ctx context.Context) error {
+	db, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// The previous reader is not in use because the lock is held.
+	prev := store.reader
+	store.reader = db
+
+	if prev != nil {
+		if err := prev.Close(); err != nil {
+			return fmt.Errorf("kv: mmdb: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// load opens the MMDB file.
+func (store *kvMMDB) load(ctx context.Context) (*maxminddb.Reader, error) {
 	path, err := file.Get(ctx, store.File)
 	defer os.Remove(path)
 	if err != nil {
-		return fmt.Errorf("kv: mmdb: %v", err)
+		return nil, fmt.Errorf("kv: mmdb: %v", err)
 	}
 
 	db, err := maxminddb.Open(path)
 	if err != nil {
-		return fmt.Errorf("kv: mmdb: %v", err)
+		return nil, fmt.Errorf("kv: mmdb: %v", err)
 	}
 
-	store.reader = db
-	return nil
+	return db, nil
 }
 
 // Closes the store.
 func (store *kvMMDB) Close() error {
+	// The refresher is stopped before the lock is held because
+	// it may be waiting on the lock to replace the reader.
+	store.mu.Lock()
+	r := store.refresh
+	store.refresh = nil
+	store.mu.Unlock()
+
+	if r != nil {
+		r.stop()
+	}
+
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
@@ -136,5 +201,6 @@ func (store *kvMMDB) Close() error {
 		return fmt.Errorf("kv: mmdb: %v", err)
 	}
 
+	store.reader = nil
 	return nil
 }
@@ -0,0 +1,368 @@
+package kv
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/secrets"
+)
+
+// redisUnlockScript deletes a lock only if it is still held by the caller. This
+// prevents a caller from releasing a lock that expired and was acquired by another
+// caller.
+var redisUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// redisCompareAndSetScript replaces a value only if the current value is equal to
+// the old value. The TTL is in milliseconds and a zero value disables expiration.
+var redisCompareAndSetScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if ARGV[3] == "0" then
+	redis.call("SET", KEYS[1], ARGV[2])
+else
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return 1
+`)
+
+// kvRedis is a read-write key-value store that is backed by a Redis server.
+//
+// Values are stored as JSON and sets are stored as Redis sets of JSON values.
+// This KV store supports per-item time-to-live (TTL), locking, and conditional
+// writes.
+type kvRedis struct {
+	// Address is the host and port of the Redis server (e.g., "localhost:6379").
+	Address string `json:"address"`
+	// DB is the Redis logical database that is selected after connecting.
+	//
+	// This is optional and defaults to 0.
+	DB int `json:"db"`
+	// Username authenticates the connection using Redis ACLs.
+	//
+	// This is optional and supports secrets interpolation.
+	Username string `json:"username"`
+	// Password authenticates the connection.
+	//
+	// This is optional and supports secrets interpolation.
+	Password string `json:"password"`
+	// EnableTLS determines if the connection uses TLS.
+	//
+	// This is optional and defaults to false.
+	EnableTLS bool `json:"enable_tls"`
+
+	mu     sync.Mutex
+	client *redis.Client
+	// tokens maps lock keys to the unique values that were used to acquire them.
+	tokens map[string]string
+}
+
+// Create a new Redis KV store.
+func newKVRedis(cfg config.Config) (*kvRedis, error) {
+	var store kvRedis
+	if err := iconfig.Decode(cfg.Settings, &store); err != nil {
+		return nil, err
+	}
+
+	if store.Address == "" {
+		return nil, fmt.Errorf("kv: redis: address: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return &store, nil
+}
+
+func (store *kvRedis) String() string {
+	return toString(store)
+}
+
+// Get retrieves a value from the store. If the value is a set, then all members
+// of the set are returned.
+func (store *kvRedis) Get(ctx context.Context, key string) (interface{}, error) {
+	ctx = context.WithoutCancel(ctx)
+
+	val, err := store.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	// Sets are stored using a different data type and must be
+	// retrieved with a different command.
+	if err != nil && redis.HasErrorPrefix(err, "WRONGTYPE") {
+		return store.getSet(ctx, key)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("kv: redis: %v", err)
+	}
+
+	var i interface{}
+	if err := json.Unmarshal(val, &i); err != nil {
+		return nil, fmt.Errorf("kv: redis: %v", err)
+	}
+
+	return i, nil
+}
+
+func (store *kvRedis) getSet(ctx context.Context, key string) (interface{}, error) {
+	members, err := store.client.SMembers(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("kv: redis: %v", err)
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	set := make([]interface{}, len(members))
+	for i, m := range members {
+		if err := json.Unmarshal([]byte(m), &set[i]); err != nil {
+			return nil, fmt.Errorf("kv: redis: %v", err)
+		}
+	}
+
+	return set, nil
+}
+
+// Set adds a value to the store.
+func (store *kvRedis) Set(ctx context.Context, key string, val interface{}) error {
+	return store.SetWithTTL(ctx, key, val, 0)
+}
+
+// SetWithTTL adds a value to the store with a time-to-live (TTL). If the TTL
+// value is zero, then the value does not expire.
+func (store *kvRedis) SetWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if err := store.client.SetArgs(ctx, key, b, redis.SetArgs{
+		TTL: redisTTL(ttl),
+	}).Err(); err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	return nil
+}
+
+// SetAddWithTTL adds a value to a set in the store. If the set doesn't exist, then
+// a new set is created. If a non-zero TTL is provided, then the expiration of the set
+// is updated with the new value.
+func (store *kvRedis) SetAddWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	pipe := store.client.TxPipeline()
+	pipe.SAdd(ctx, key, b)
+	if ttl != 0 {
+		pipe.ExpireAt(ctx, key, time.Unix(ttl, 0))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	return nil
+}
+
+// SetIfNotExistsWithTTL adds a value to the store with a time-to-live (TTL) if the
+// key does not exist or has expired. If the key exists, then this returns
+// ErrConditionFailed.
+func (store *kvRedis) SetIfNotExistsWithTTL(ctx context.Context, key string, val interface{}, ttl int64) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	err = store.client.SetArgs(ctx, key, b, redis.SetArgs{
+		Mode: "NX",
+		TTL:  redisTTL(ttl),
+	}).Err()
+	if errors.Is(err, redis.Nil) {
+		return ErrConditionFailed
+	}
+
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	return nil
+}
+
+// CompareAndSetWithTTL replaces a value in the store with a time-to-live (TTL) if
+// the current value is equal to the old value. Values are compared using their
+// JSON encoding. If the values are not equal or the key does not exist, then this
+// returns ErrConditionFailed.
+func (store *kvRedis) CompareAndSetWithTTL(ctx context.Context, key string, old, val interface{}, ttl int64) error {
+	ob, err := json.Marshal(old)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	vb, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	ok, err := redisCompareAndSetScript.Run(ctx, store.client, []string{key}, ob, vb, redisTTL(ttl).Milliseconds()).Bool()
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	if !ok {
+		return ErrConditionFailed
+	}
+
+	return nil
+}
+
+// Lock adds an item to the store if it does not already exist. If the item already
+// exists, then this returns ErrNoLock.
+//
+// Each lock is assigned a unique token that is checked when the lock is released.
+func (store *kvRedis) Lock(ctx context.Context, key string, ttl int64) error {
+	token := uuid.NewString()
+
+	ctx = context.WithoutCancel(ctx)
+	ok, err := store.client.SetNX(ctx, key, token, redisTTL(ttl)).Result()
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	if !ok {
+		return ErrNoLock
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.tokens[key] = token
+	return nil
+}
+
+// Unlock removes an item from the store if it is held by the caller.
+func (store *kvRedis) Unlock(ctx context.Context, key string) error {
+	store.mu.Lock()
+	token, ok := store.tokens[key]
+	delete(store.tokens, key)
+	store.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if err := redisUnlockScript.Run(ctx, store.client, []string{key}, token).Err(); err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	return nil
+}
+
+// IsEnabled returns true if the store is ready for use.
+func (store *kvRedis) IsEnabled() bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.client != nil
+}
+
+// Setup creates a new Redis client and verifies the connection.
+func (store *kvRedis) Setup(ctx context.Context) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// Avoids unnecessary setup.
+	if store.client != nil {
+		return nil
+	}
+
+	username, err := secrets.Interpolate(ctx, store.Username)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	password, err := secrets.Interpolate(ctx, store.Password)
+	if err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	opts := &redis.Options{
+		Addr:     store.Address,
+		DB:       store.DB,
+		Username: username,
+		Password: password,
+	}
+
+	if store.EnableTLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.WithoutCancel(ctx)).Err(); err != nil {
+		_ = client.Close()
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	store.client = client
+	store.tokens = make(map[string]string)
+
+	return nil
+}
+
+// Closes the store.
+func (store *kvRedis) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// Avoids unnecessary closing.
+	if store.client == nil {
+		return nil
+	}
+
+	if err := store.client.Close(); err != nil {
+		return fmt.Errorf("kv: redis: %v", err)
+	}
+
+	store.client = nil
+	return nil
+}
+
+// redisTTL converts a Unix time TTL to a duration. Zero values are not converted,
+// which disables expiration.
+func redisTTL(ttl int64) time.Duration {
+	if ttl == 0 {
+		return 0
+	}
+
+	// Redis does not accept expirations in the past, so the value is
+	// expired as soon as possible.
+	d := time.Until(time.Unix(ttl, 0))
+	if d < time.Millisecond {
+		return time.Millisecond
+	}
+
+	return d
+}
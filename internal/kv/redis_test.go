@@ -0,0 +1,216 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+func newTestKVRedis(t *testing.T) (*kvRedis, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	store, err := newKVRedis(config.Config{
+		Settings: map[string]interface{}{
+			"address": srv.Addr(),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Setup(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store, srv
+}
+
+func TestKVRedis(t *testing.T) {
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		set      func(*kvRedis) error
+		key      string
+		expected interface{}
+	}{
+		{
+			"string",
+			func(s *kvRedis) error { return s.Set(ctx, "a", "b") },
+			"a",
+			"b",
+		},
+		{
+			"object",
+			func(s *kvRedis) error { return s.Set(ctx, "a", map[string]interface{}{"b": "c"}) },
+			"a",
+			map[string]interface{}{"b": "c"},
+		},
+		{
+			"number",
+			func(s *kvRedis) error { return s.SetWithTTL(ctx, "a", 1, time.Now().Add(time.Hour).Unix()) },
+			"a",
+			float64(1),
+		},
+		{
+			"set",
+			func(s *kvRedis) error {
+				for _, v := range []string{"b", "c", "b"} {
+					if err := s.SetAddWithTTL(ctx, "a", v, 0); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+			"a",
+			[]interface{}{"b", "c"},
+		},
+		{
+			"missing",
+			func(s *kvRedis) error { return nil },
+			"a",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, _ := newTestKVRedis(t)
+			if err := test.set(store); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := store.Get(ctx, test.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Set members are not ordered.
+			if s, ok := v.([]interface{}); ok {
+				sort.Slice(s, func(i, j int) bool { return s[i].(string) < s[j].(string) })
+			}
+
+			if !reflect.DeepEqual(test.expected, v) {
+				t.Errorf("expected %v, got %v", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestKVRedisTTL(t *testing.T) {
+	ctx := context.TODO()
+	store, srv := newTestKVRedis(t)
+
+	ttl := time.Now().Add(time.Minute).Unix()
+	if err := store.SetWithTTL(ctx, "a", "b", ttl); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.SetAddWithTTL(ctx, "c", "d", ttl); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.FastForward(2 * time.Minute)
+
+	for _, key := range []string{"a", "c"} {
+		v, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v != nil {
+			t.Errorf("expected nil, got %v", v)
+		}
+	}
+}
+
+func TestKVRedisLock(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("lock", func(t *testing.T) {
+		store, _ := newTestKVRedis(t)
+
+		ttl := time.Now().Add(time.Minute).Unix()
+		if err := store.Lock(ctx, "a", ttl); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Lock(ctx, "a", ttl); !errors.Is(err, ErrNoLock) {
+			t.Fatalf("expected %v, got %v", ErrNoLock, err)
+		}
+
+		if err := store.Unlock(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Lock(ctx, "a", ttl); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unlock with wrong token", func(t *testing.T) {
+		store, srv := newTestKVRedis(t)
+
+		if err := store.Lock(ctx, "a", time.Now().Add(time.Minute).Unix()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The lock is held by another caller with a different token.
+		srv.Set("a", "other")
+
+		if err := store.Unlock(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		if v, _ := srv.Get("a"); v != "other" {
+			t.Errorf("expected lock to be held, got %q", v)
+		}
+	})
+
+	t.Run("unlock after expiration", func(t *testing.T) {
+		first, srv := newTestKVRedis(t)
+
+		second, err := newKVRedis(config.Config{
+			Settings: map[string]interface{}{
+				"address": srv.Addr(),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := second.Setup(ctx); err != nil {
+			t.Fatal(err)
+		}
+		defer second.Close()
+
+		if err := first.Lock(ctx, "a", time.Now().Add(time.Minute).Unix()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The first lock expires and the second caller acquires the lock.
+		srv.FastForward(2 * time.Minute)
+		if err := second.Lock(ctx, "a", time.Now().Add(time.Minute).Unix()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The first caller cannot release the lock that is held by the second caller.
+		if err := first.Unlock(ctx, "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := first.Lock(ctx, "a", time.Now().Add(time.Minute).Unix()); !errors.Is(err, ErrNoLock) {
+			t.Fatalf("expected %v, got %v", ErrNoLock, err)
+		}
+	})
+}
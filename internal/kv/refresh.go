@@ -0,0 +1,55 @@
+package kv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/brexhq/substation/v2/internal/log"
+)
+
+// refresher periodically calls a function in a separate goroutine until it is
+// stopped. This is used by read-only stores to reload data from their source.
+type refresher struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newRefresher starts a refresher that calls fn on every interval. Errors returned
+// by fn are logged and otherwise ignored, so stores continue to use the last data
+// that was successfully loaded.
+func newRefresher(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) *refresher {
+	r := &refresher{
+		done: make(chan struct{}),
+	}
+
+	// The refresher outlives the context that created it.
+	ctx = context.WithoutCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				if err := fn(ctx); err != nil {
+					log.WithField("kv_store", name).WithField("error", err).Debug("Failed to refresh KV store.")
+				}
+			}
+		}
+	}()
+
+	return r
+}
+
+// stop stops the refresher and waits for any in-progress refresh to finish.
+func (r *refresher) stop() {
+	close(r.done)
+	r.wg.Wait()
+}
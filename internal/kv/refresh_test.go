@@ -0,0 +1,175 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxmind/mmdbwriter"
+	"github.com/maxmind/mmdbwriter/mmdbtype"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+// refreshTestMMDB returns an MMDB file that maps 10.0.0.0/8 to a value.
+func refreshTestMMDB(t *testing.T, val string) []byte {
+	t.Helper()
+
+	tree, err := mmdbwriter.New(mmdbwriter.Options{
+		DatabaseType:            "Test",
+		IncludeReservedNetworks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	if err := tree.Insert(network, mmdbtype.Map{"value": mmdbtype.String(val)}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := tree.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// refreshTestWrite atomically replaces the contents of a file so that a
+// refresh never reads a partially written file.
+func refreshTestWrite(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshInterval(t *testing.T) {
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		typ      string
+		settings map[string]interface{}
+		key      string
+		// before and after are the file contents before and after the refresh.
+		before []byte
+		after  []byte
+		// invalid are file contents that cannot be loaded.
+		invalid  [][]byte
+		expected [2]string
+	}{
+		{
+			"text_file",
+			"text_file",
+			nil,
+			"b",
+			[]byte("a\n"),
+			[]byte("a\nb\n"),
+			[][]byte{{}},
+			[2]string{"false", "true"},
+		},
+		{
+			"csv_file",
+			"csv_file",
+			map[string]interface{}{"column": "key"},
+			"a",
+			[]byte("key,value\na,1\n"),
+			[]byte("key,value\na,2\n"),
+			[][]byte{{}, []byte("key,value\na,2,3\n")},
+			[2]string{"map[value:1]", "map[value:2]"},
+		},
+		{
+			"json_file",
+			"json_file",
+			nil,
+			"a",
+			[]byte(`{"a":1}`),
+			[]byte(`{"a":2}`),
+			[][]byte{{}, []byte(`{"a":`)},
+			[2]string{"1", "2"},
+		},
+		{
+			"mmdb",
+			"mmdb",
+			nil,
+			"10.0.0.1",
+			refreshTestMMDB(t, "before"),
+			refreshTestMMDB(t, "after"),
+			[][]byte{{}, []byte("not an mmdb file")},
+			[2]string{"map[value:before]", "map[value:after]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			refreshTestWrite(t, path, test.before)
+
+			settings := map[string]interface{}{
+				"file":             path,
+				"refresh_interval": "10ms",
+			}
+			for k, v := range test.settings {
+				settings[k] = v
+			}
+
+			store, err := New(config.Config{Type: test.typ, Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Setup(ctx); err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			get := func() string {
+				v, err := store.Get(ctx, test.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return fmt.Sprint(v)
+			}
+
+			if v := get(); v != test.expected[0] {
+				t.Fatalf("expected %s, got %s", test.expected[0], v)
+			}
+
+			// The store is refreshed after the file is replaced.
+			refreshTestWrite(t, path, test.after)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for get() != test.expected[1] {
+				if time.Now().After(deadline) {
+					t.Fatalf("expected %s, got %s", test.expected[1], get())
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// The store keeps the previous data if the file cannot be loaded.
+			for _, data := range test.invalid {
+				refreshTestWrite(t, path, data)
+				time.Sleep(100 * time.Millisecond)
+
+				if v := get(); v != test.expected[1] {
+					t.Errorf("expected %s, got %s, %q", test.expected[1], v, data)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/brexhq/substation/v2/config"
 
@@ -31,9 +32,16 @@ import (
 type kvTextFile struct {
 	// File contains the location of the text file. This can be either a path on local
 	// disk, an HTTP(S) URL, or an AWS S3 URL.
-	File  string `json:"file"`
-	mu    sync.Mutex
-	items []string
+	File string `json:"file"`
+	// RefreshInterval is the amount of time between reloading the text file. If the
+	// file cannot be reloaded, then the store continues to use the previous data.
+	//
+	// This is optional and defaults to never reloading the file.
+	RefreshInterval string `json:"refresh_interval"`
+
+	mu      sync.Mutex
+	items   []string
+	refresh *refresher
 }
 
 // Create a new text file KV store.
@@ -47,6 +55,12 @@ func newKVTextFile(cfg config.Config) (*kvTextFile, error) {
 		return nil, fmt.Errorf("kv: text_file: options %+v: %v", &store, iconfig.ErrMissingRequiredOption)
 	}
 
+	if store.RefreshInterval != "" {
+		if _, err := time.ParseDuration(store.RefreshInterval); err != nil {
+			return nil, fmt.Errorf("kv: text_file: refresh_interval: %v", err)
+		}
+	}
+
 	return &store, nil
 }
 
@@ -95,28 +109,78 @@ func (store *kvTextFile) Setup(ctx context.Context) error {
 		return nil
 	}
 
+	items, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+	store.items = items
+
+	if store.RefreshInterval != "" {
+		// The interval is validated when the store is created.
+		interval, _ := time.ParseDuration(store.RefreshInterval)
+		store.refresh = newRefresher(ctx, "text_file", interval, store.reload)
+	}
+
+	return nil
+}
+
+// reload reads the text file and replaces the items in the store. If the file
+// cannot be read, then the items are not changed.
+func (store *kvTextFile) reload(ctx context.Context) error {
+	items, err := store.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.items = items
+	return nil
+}
+
+// load reads the text file into a new slice.
+func (store *kvTextFile) load(ctx context.Context) ([]string, error) {
 	path, err := file.Get(ctx, store.File)
 	defer os.Remove(path)
 	if err != nil {
-		return fmt.Errorf("kv: text_file: %v", err)
+		return nil, fmt.Errorf("kv: text_file: %v", err)
 	}
 
 	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("kv: text_file: %v", err)
+		return nil, fmt.Errorf("kv: text_file: %v", err)
 	}
 	defer f.Close()
 
+	// items is never nil so that the store is enabled
+	// when the file is empty.
+	items := []string{}
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		store.items = append(store.items, scanner.Text())
+		items = append(items, scanner.Text())
 	}
 
-	return nil
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("kv: text_file: %v", err)
+	}
+
+	return items, nil
 }
 
 // Closes the store.
 func (store *kvTextFile) Close() error {
+	// The refresher is stopped before the lock is held because
+	// it may be waiting on the lock to replace the items.
+	store.mu.Lock()
+	r := store.refresh
+	store.refresh = nil
+	store.mu.Unlock()
+
+	if r != nil {
+		r.stop()
+	}
+
 	store.mu.Lock()
 	defer store.mu.Unlock()
 
@@ -7,10 +7,26 @@ import (
 	"os"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/brexhq/substation/v2/internal/secrets"
 )
 
 var log = logrus.New()
 
+// redactFormatter wraps a logrus Formatter and removes secrets from log entries.
+type redactFormatter struct {
+	logrus.Formatter
+}
+
+func (f *redactFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(secrets.Redact(string(b))), nil
+}
+
 // Debug wraps logrus Debug function with stack information
 func Debug(args ...interface{}) {
 	log.Debug(args...)
@@ -27,6 +43,8 @@ func WithField(k string, v interface{}) *logrus.Entry {
 }
 
 func init() {
+	log.SetFormatter(&redactFormatter{log.Formatter})
+
 	if _, ok := os.LookupEnv("SUBSTATION_DEBUG"); ok {
 		log.SetLevel(logrus.DebugLevel)
 		return
@@ -3,6 +3,8 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/brexhq/substation/v2/config"
 
@@ -40,11 +42,98 @@ type Generator interface {
 	Generate(context.Context, Data) error
 }
 
+// Flusher is implemented by generators that buffer metrics before sending them
+// to external services.
+type Flusher interface {
+	Flush(context.Context) error
+}
+
+// Flush sends every buffered metric to the external service. If the generator
+// does not implement Flusher, then this does nothing.
+func Flush(ctx context.Context, gen Generator) error {
+	if f, ok := gen.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
 func New(ctx context.Context, cfg config.Config) (Generator, error) {
 	switch cfg.Type {
 	case "aws_cloudwatch_embedded_metrics":
 		return newAWSCloudWatchEmbeddedMetrics(ctx, cfg)
+	case "otlp_metrics":
+		return newOTLPMetrics(ctx, cfg)
+	case "prometheus":
+		return newPrometheus(ctx, cfg)
+	case "statsd":
+		return newStatsD(ctx, cfg)
 	default:
 		return nil, fmt.Errorf("metrics: new: type %q settings %+v: %v", cfg.Type, cfg.Settings, iconfig.ErrInvalidFactoryInput)
 	}
 }
+
+// snakeCase converts UpperCamelCase to snake_case and replaces characters
+// that are not allowed in metric and label names.
+func snakeCase(s string) string {
+	var b strings.Builder
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			// Acronyms (e.g., HTTPRequests) are split only at the last letter.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+
+			b.WriteRune(unicode.ToLower(r))
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	// Invalid characters can create repeated separators.
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' })
+	out := strings.Join(parts, "_")
+	if out != "" && out[0] >= '0' && out[0] <= '9' {
+		out = "_" + out
+	}
+
+	return out
+}
+
+// toFloat64 converts a numeric value to a float.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
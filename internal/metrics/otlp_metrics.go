@@ -0,0 +1,316 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+var (
+	// otlpMu protects otlpProviders.
+	otlpMu sync.Mutex
+	// otlpProviders contains a meter provider for each configuration. Every generator
+	// that uses the same configuration shares a provider, so metrics from many
+	// transforms are exported together.
+	otlpProviders = make(map[string]*otlpProvider)
+)
+
+type otlpMetricsConfig struct {
+	// Protocol is the OTLP transport protocol. Must be one of:
+	//
+	// - http: OTLP/HTTP with protobuf encoding
+	//
+	// - grpc: OTLP/gRPC
+	//
+	// This is optional and defaults to http.
+	Protocol string `json:"protocol"`
+	// Endpoint is the host and port of the OTLP receiver (e.g., localhost:4318).
+	//
+	// This is optional and defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment
+	// variable or the default endpoint for the protocol.
+	Endpoint string `json:"endpoint"`
+	// Insecure disables TLS.
+	//
+	// This is optional and defaults to false.
+	Insecure bool `json:"insecure"`
+	// Headers are sent with every export request (e.g., API keys).
+	//
+	// This is optional and defaults to no headers.
+	Headers map[string]string `json:"headers"`
+	// ExportInterval is the amount of time between exports.
+	//
+	// This is optional and defaults to 60s.
+	ExportInterval string `json:"export_interval"`
+	// Namespace is prefixed to the name of every metric.
+	//
+	// This is optional and defaults to "substation".
+	Namespace string `json:"namespace"`
+}
+
+// otlpMetrics exports metrics to an OpenTelemetry Protocol (OTLP) receiver, such
+// as the OpenTelemetry Collector. Attributes are exported as metric attributes.
+// Read more about OTLP here: https://opentelemetry.io/docs/specs/otlp/.
+//
+// Metrics are converted based on the type of their value:
+//
+// - Durations (time.Duration) are recorded by a histogram in seconds.
+//
+// - All other numbers are added to a counter.
+//
+// Metrics are exported periodically, when the generator is flushed, and when the
+// generator is closed. Applications that are frozen between invocations (e.g.,
+// AWS Lambda) must flush the generator to avoid dropping metrics.
+type otlpMetrics struct {
+	conf otlpMetricsConfig
+
+	provider  *otlpProvider
+	closeOnce sync.Once
+	meter     metric.Meter
+
+	mu         sync.Mutex
+	counters   map[string]metric.Float64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+func newOTLPMetrics(ctx context.Context, cfg config.Config) (*otlpMetrics, error) {
+	conf := otlpMetricsConfig{}
+	if err := iconfig.Decode(cfg.Settings, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Protocol == "" {
+		conf.Protocol = "http"
+	}
+
+	if conf.Namespace == "" {
+		conf.Namespace = "substation"
+	}
+
+	provider, err := otlpGetProvider(ctx, conf)
+	if err != nil {
+		return nil, fmt.Errorf("metrics otlp_metrics: %v", err)
+	}
+
+	return &otlpMetrics{
+		conf:       conf,
+		provider:   provider,
+		meter:      provider.p.Meter(metricsApplication),
+		counters:   make(map[string]metric.Float64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}, nil
+}
+
+func (m *otlpMetrics) Generate(ctx context.Context, data Data) error {
+	name := snakeCase(data.Name)
+	if name == "" {
+		return nil
+	}
+
+	if m.conf.Namespace != "" {
+		name = fmt.Sprintf("%s.%s", m.conf.Namespace, name)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(data.Attributes))
+	for k, v := range data.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	opt := metric.WithAttributes(attrs...)
+
+	switch v := data.Value.(type) {
+	case time.Duration:
+		h, err := m.histogram(name)
+		if err != nil {
+			return fmt.Errorf("metrics otlp_metrics: %v", err)
+		}
+
+		h.Record(ctx, v.Seconds(), opt)
+	default:
+		f, ok := toFloat64(v)
+		if !ok {
+			return fmt.Errorf("metrics otlp_metrics: %s: unsupported value type %T", data.Name, data.Value)
+		}
+
+		c, err := m.counter(name)
+		if err != nil {
+			return fmt.Errorf("metrics otlp_metrics: %v", err)
+		}
+
+		c.Add(ctx, f, opt)
+	}
+
+	return nil
+}
+
+// Flush exports every metric that was recorded since the last export.
+func (m *otlpMetrics) Flush(ctx context.Context) error {
+	if err := m.provider.p.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("metrics otlp_metrics: %v", err)
+	}
+
+	return nil
+}
+
+// Close exports every recorded metric. The meter provider is shut down when
+// every generator that shares it is closed.
+func (m *otlpMetrics) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		err = otlpCloseProvider(m.conf, m.provider)
+	})
+
+	if err != nil {
+		return fmt.Errorf("metrics otlp_metrics: %v", err)
+	}
+
+	return nil
+}
+
+func (m *otlpMetrics) counter(name string) (metric.Float64Counter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if c, ok := m.counters[name]; ok {
+		return c, nil
+	}
+
+	c, err := m.meter.Float64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.counters[name] = c
+	return c, nil
+}
+
+func (m *otlpMetrics) histogram(name string) (metric.Float64Histogram, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if h, ok := m.histograms[name]; ok {
+		return h, nil
+	}
+
+	h, err := m.meter.Float64Histogram(name, metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	m.histograms[name] = h
+	return h, nil
+}
+
+// otlpProvider is a meter provider that is shared by all generators that use the
+// same configuration.
+type otlpProvider struct {
+	p    *sdkmetric.MeterProvider
+	refs int
+}
+
+// otlpGetProvider returns the meter provider for the configuration and creates
+// it if it does not exist.
+func otlpGetProvider(ctx context.Context, conf otlpMetricsConfig) (*otlpProvider, error) {
+	otlpMu.Lock()
+	defer otlpMu.Unlock()
+
+	sig := fmt.Sprint(conf)
+	if p, ok := otlpProviders[sig]; ok {
+		p.refs++
+		return p, nil
+	}
+
+	var exp sdkmetric.Exporter
+	switch conf.Protocol {
+	case "http":
+		var opts []otlpmetrichttp.Option
+		if conf.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(conf.Endpoint))
+		}
+
+		if conf.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(conf.Headers))
+		}
+
+		e, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		exp = e
+	case "grpc":
+		var opts []otlpmetricgrpc.Option
+		if conf.Endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(conf.Endpoint))
+		}
+
+		if conf.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+
+		if len(conf.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(conf.Headers))
+		}
+
+		e, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		exp = e
+	default:
+		return nil, fmt.Errorf("protocol %s: %v", conf.Protocol, iconfig.ErrInvalidOption)
+	}
+
+	var readerOpts []sdkmetric.PeriodicReaderOption
+	if conf.ExportInterval != "" {
+		dur, err := time.ParseDuration(conf.ExportInterval)
+		if err != nil {
+			return nil, fmt.Errorf("export_interval: %v", err)
+		}
+
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(dur))
+	}
+
+	p := &otlpProvider{
+		p: sdkmetric.NewMeterProvider(
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp, readerOpts...)),
+			sdkmetric.WithResource(resource.Default()),
+		),
+		refs: 1,
+	}
+
+	otlpProviders[sig] = p
+	return p, nil
+}
+
+// otlpCloseProvider releases the meter provider for the configuration. If no
+// generators use the provider, then it is shut down, which exports every
+// recorded metric.
+func otlpCloseProvider(conf otlpMetricsConfig, p *otlpProvider) error {
+	otlpMu.Lock()
+	defer otlpMu.Unlock()
+
+	p.refs--
+	if p.refs > 0 {
+		return p.p.ForceFlush(context.Background())
+	}
+
+	delete(otlpProviders, fmt.Sprint(conf))
+	return p.p.Shutdown(context.Background())
+}
@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+func TestOTLPMetrics(t *testing.T) {
+	ctx := context.TODO()
+
+	// Metrics are exported to an OTLP receiver.
+	var (
+		mu       sync.Mutex
+		requests []*collectorpb.ExportMetricsServiceRequest
+	)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		req := &collectorpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer receiver.Close()
+
+	conf := map[string]interface{}{
+		"endpoint":        strings.TrimPrefix(receiver.URL, "http://"),
+		"insecure":        true,
+		"export_interval": "1h",
+	}
+
+	m, err := newOTLPMetrics(ctx, config.Config{Settings: conf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []Data{
+		{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+		{Name: "MessagesCount", Value: 2, Attributes: map[string]string{"TransformID": "a"}},
+		{Name: "TransformDuration", Value: 500 * time.Millisecond, Attributes: map[string]string{"TransformID": "a"}},
+	}
+
+	for _, d := range data {
+		if err := m.Generate(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Generate(ctx, Data{Name: "MessagesCount", Value: "a"}); err == nil {
+		t.Error("expected error for unsupported value type")
+	}
+
+	// Metrics are exported when the generator is flushed.
+	if err := m.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Generate(ctx, Data{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Metrics are exported and the provider is shut down when the generator is closed.
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	otlpMu.Lock()
+	_, ok := otlpProviders[fmt.Sprint(m.conf)]
+	otlpMu.Unlock()
+
+	if ok {
+		t.Error("expected provider to be removed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	// Counters are cumulative, so the second export includes the first.
+	expected := []map[string]string{
+		{
+			"substation.messages_count":     "3 TransformID=a",
+			"substation.transform_duration": "0.5 1 s TransformID=a",
+		},
+		{
+			"substation.messages_count":     "4 TransformID=a",
+			"substation.transform_duration": "0.5 1 s TransformID=a",
+		},
+	}
+
+	for i, req := range requests {
+		results := otlpTestResults(req)
+		for k, v := range expected[i] {
+			if results[k] != v {
+				t.Errorf("%d: %s: expected %s, got %s", i, k, v, results[k])
+			}
+		}
+	}
+}
+
+// otlpTestResults converts the data points in a request to strings.
+func otlpTestResults(req *collectorpb.ExportMetricsServiceRequest) map[string]string {
+	results := make(map[string]string)
+	for _, rm := range req.GetResourceMetrics() {
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, metric := range sm.GetMetrics() {
+				if s := metric.GetSum(); s != nil {
+					for _, dp := range s.GetDataPoints() {
+						results[metric.GetName()] = fmt.Sprintf("%v %s", dp.GetAsDouble(), otlpTestAttributes(dp.GetAttributes()))
+					}
+				}
+
+				if h := metric.GetHistogram(); h != nil {
+					for _, dp := range h.GetDataPoints() {
+						results[metric.GetName()] = fmt.Sprintf("%v %d %s %s", dp.GetSum(), dp.GetCount(), metric.GetUnit(), otlpTestAttributes(dp.GetAttributes()))
+					}
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// otlpTestAttributes converts attributes to a string (e.g., a=b,c=d).
+func otlpTestAttributes(kvs []*commonpb.KeyValue) string {
+	attrs := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		attrs = append(attrs, fmt.Sprintf("%s=%s", kv.GetKey(), kv.GetValue().GetStringValue()))
+	}
+	sort.Strings(attrs)
+
+	return strings.Join(attrs, ",")
+}
@@ -0,0 +1,405 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+const (
+	// promOtherValue replaces attribute values that exceed the cardinality limit.
+	promOtherValue = "other"
+)
+
+var (
+	// promBuckets are the default histogram buckets, in seconds.
+	promBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// promMu protects promRegistries.
+	promMu sync.Mutex
+	// promRegistries contains a registry for each listener. Every generator that uses
+	// the same address shares a registry, so metrics from many transforms are exposed
+	// on a single endpoint.
+	promRegistries = make(map[string]*promRegistry)
+	// promEscape escapes label values in the text format.
+	promEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+type prometheusConfig struct {
+	// Address is the address that the HTTP listener binds to.
+	//
+	// This is optional and defaults to ":2112".
+	Address string `json:"address"`
+	// Path is the HTTP path that metrics are exposed on.
+	//
+	// This is optional and defaults to "/metrics".
+	Path string `json:"path"`
+	// Namespace is prefixed to the name of every metric.
+	//
+	// This is optional and defaults to "substation".
+	Namespace string `json:"namespace"`
+	// Buckets are the upper bounds, in seconds, of the buckets used by histograms.
+	//
+	// This is optional and defaults to buckets between 5ms and 10s.
+	Buckets []float64 `json:"buckets"`
+	// MaxAttributeValues is the maximum number of unique values for each attribute
+	// of each metric. Values above this limit are replaced with "other".
+	//
+	// This is optional and defaults to 100.
+	MaxAttributeValues int `json:"max_attribute_values"`
+}
+
+// prometheus aggregates metrics and exposes them in the Prometheus text format
+// on an HTTP listener. Read more about the format here:
+// https://prometheus.io/docs/instrumenting/exposition_formats/.
+//
+// Metrics are converted based on the type of their value:
+//
+// - Durations (time.Duration) are observed by a histogram in seconds.
+//
+// - All other numbers are added to a counter.
+//
+// Metric names are converted to snake case (e.g., MessagesCount becomes
+// substation_messages_count_total) and attributes are converted to labels.
+type prometheus struct {
+	conf prometheusConfig
+
+	registry *promRegistry
+}
+
+func newPrometheus(_ context.Context, cfg config.Config) (*prometheus, error) {
+	conf := prometheusConfig{}
+	if err := iconfig.Decode(cfg.Settings, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Address == "" {
+		conf.Address = ":2112"
+	}
+
+	if conf.Path == "" {
+		conf.Path = "/metrics"
+	}
+
+	if conf.Namespace == "" {
+		conf.Namespace = "substation"
+	}
+
+	if conf.Buckets == nil {
+		conf.Buckets = promBuckets
+	}
+
+	if conf.MaxAttributeValues == 0 {
+		conf.MaxAttributeValues = 100
+	}
+
+	buckets := make([]float64, len(conf.Buckets))
+	copy(buckets, conf.Buckets)
+	sort.Float64s(buckets)
+
+	r, err := promGetRegistry(conf.Address, conf.Path)
+	if err != nil {
+		return nil, fmt.Errorf("metrics prometheus: %v", err)
+	}
+
+	m := &prometheus{
+		conf:     conf,
+		registry: r,
+	}
+	m.conf.Buckets = buckets
+
+	return m, nil
+}
+
+func (m *prometheus) Generate(ctx context.Context, data Data) error {
+	name := promName(m.conf.Namespace, data.Name)
+	if name == "" {
+		return nil
+	}
+
+	switch v := data.Value.(type) {
+	case time.Duration:
+		m.registry.observe(name+"_seconds", data.Attributes, v.Seconds(), m.conf.Buckets, m.conf.MaxAttributeValues)
+	default:
+		f, ok := toFloat64(v)
+		if !ok {
+			return fmt.Errorf("metrics prometheus: %s: unsupported value type %T", data.Name, data.Value)
+		}
+
+		m.registry.add(name+"_total", data.Attributes, f, m.conf.MaxAttributeValues)
+	}
+
+	return nil
+}
+
+// promGetRegistry returns the registry for the address and starts the listener
+// if it is not already running.
+func promGetRegistry(address, path string) (*promRegistry, error) {
+	promMu.Lock()
+	defer promMu.Unlock()
+
+	if r, ok := promRegistries[address]; ok {
+		r.handle(path)
+		return r, nil
+	}
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newPromRegistry()
+	r.handle(path)
+
+	srv := &http.Server{
+		Handler:           r.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	promRegistries[address] = r
+	return r, nil
+}
+
+// promRegistry is a concurrency-safe collection of metrics.
+type promRegistry struct {
+	mux   *http.ServeMux
+	paths map[string]struct{}
+
+	mu      sync.Mutex
+	metrics map[string]*promMetric
+}
+
+func newPromRegistry() *promRegistry {
+	return &promRegistry{
+		mux:     http.NewServeMux(),
+		paths:   make(map[string]struct{}),
+		metrics: make(map[string]*promMetric),
+	}
+}
+
+// handle registers the exposition handler on the path. This must be called
+// while holding promMu.
+func (r *promRegistry) handle(path string) {
+	if _, ok := r.paths[path]; ok {
+		return
+	}
+
+	r.paths[path] = struct{}{}
+	r.mux.Handle(path, r)
+}
+
+func (r *promRegistry) add(name string, attr map[string]string, val float64, limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.metric(name, "counter").get(attr, limit)
+	s.value += val
+}
+
+func (r *promRegistry) observe(name string, attr map[string]string, val float64, buckets []float64, limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.metric(name, "histogram")
+	if m.buckets == nil {
+		m.buckets = buckets
+	}
+
+	s := m.get(attr, limit)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(m.buckets))
+	}
+
+	for i, b := range m.buckets {
+		if val <= b {
+			s.counts[i]++
+		}
+	}
+
+	s.value += val
+	s.count++
+}
+
+// metric must be called while holding the lock.
+func (r *promRegistry) metric(name, typ string) *promMetric {
+	m, ok := r.metrics[name]
+	if !ok {
+		m = &promMetric{
+			typ:    typ,
+			values: make(map[string]map[string]struct{}),
+			series: make(map[string]*promSeries),
+		}
+		r.metrics[name] = m
+	}
+
+	return m
+}
+
+// ServeHTTP writes every metric in the text exposition format.
+func (r *promRegistry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.metrics))
+	for n := range r.metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range names {
+		r.metrics[n].write(&b, n)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+type promMetric struct {
+	typ     string
+	buckets []float64
+
+	// values contains the unique values of each attribute, which are used to
+	// enforce the cardinality limit.
+	values map[string]map[string]struct{}
+	series map[string]*promSeries
+}
+
+type promSeries struct {
+	labels string
+
+	value  float64
+	count  uint64
+	counts []uint64
+}
+
+// get returns the series for the attributes. Attribute values that exceed
+// the cardinality limit are replaced.
+func (m *promMetric) get(attr map[string]string, limit int) *promSeries {
+	keys := make([]string, 0, len(attr))
+	for k := range attr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := attr[k]
+
+		vals, ok := m.values[k]
+		if !ok {
+			vals = make(map[string]struct{})
+			m.values[k] = vals
+		}
+
+		if _, ok := vals[v]; !ok {
+			if len(vals) >= limit {
+				v = promOtherValue
+			} else {
+				vals[v] = struct{}{}
+			}
+		}
+
+		labels = append(labels, fmt.Sprintf(`%s="%s"`, promLabel(k), promEscape.Replace(v)))
+	}
+
+	l := strings.Join(labels, ",")
+	s, ok := m.series[l]
+	if !ok {
+		s = &promSeries{labels: l}
+		m.series[l] = s
+	}
+
+	return s
+}
+
+func (m *promMetric) write(b *strings.Builder, name string) {
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, m.typ)
+	for _, k := range keys {
+		s := m.series[k]
+
+		if m.typ == "counter" {
+			fmt.Fprintf(b, "%s%s %s\n", name, promLabels(s.labels), promFormat(s.value))
+			continue
+		}
+
+		for i, bucket := range m.buckets {
+			fmt.Fprintf(b, "%s_bucket%s %d\n", name, promLabels(s.labels, fmt.Sprintf("le=%q", promFormat(bucket))), s.counts[i])
+		}
+
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, promLabels(s.labels, `le="+Inf"`), s.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", name, promLabels(s.labels), promFormat(s.value))
+		fmt.Fprintf(b, "%s_count%s %d\n", name, promLabels(s.labels), s.count)
+	}
+}
+
+// promLabels joins labels into a label set (e.g., {a="b",c="d"}).
+func promLabels(labels ...string) string {
+	var l []string
+	for _, s := range labels {
+		if s != "" {
+			l = append(l, s)
+		}
+	}
+
+	if len(l) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(l, ",") + "}"
+}
+
+// promName converts a metric name to snake case and adds the namespace.
+func promName(namespace, name string) string {
+	n := snakeCase(name)
+	if n == "" {
+		return ""
+	}
+
+	if namespace == "" {
+		return n
+	}
+
+	return snakeCase(namespace) + "_" + n
+}
+
+// promLabel converts an attribute to a valid label name.
+func promLabel(s string) string {
+	l := snakeCase(s)
+	if l == "" {
+		return "_"
+	}
+
+	return l
+}
+
+func promFormat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+func TestPrometheus(t *testing.T) {
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		data     []Data
+		expected string
+	}{
+		{
+			"counter",
+			nil,
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 2, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 3.5, Attributes: map[string]string{"TransformID": "b"}},
+				{Name: "BytesCount", Value: int64(10)},
+			},
+			`# TYPE substation_bytes_count_total counter
+substation_bytes_count_total 10
+# TYPE substation_messages_count_total counter
+substation_messages_count_total{transform_id="a"} 3
+substation_messages_count_total{transform_id="b"} 3.5
+`,
+		},
+		{
+			"histogram",
+			map[string]interface{}{
+				"namespace": "test",
+				"buckets":   []interface{}{1, 0.1},
+			},
+			[]Data{
+				{Name: "TransformDuration", Value: 50 * time.Millisecond, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "TransformDuration", Value: 500 * time.Millisecond, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "TransformDuration", Value: 2 * time.Second, Attributes: map[string]string{"TransformID": "a"}},
+			},
+			`# TYPE test_transform_duration_seconds histogram
+test_transform_duration_seconds_bucket{transform_id="a",le="0.1"} 1
+test_transform_duration_seconds_bucket{transform_id="a",le="1"} 2
+test_transform_duration_seconds_bucket{transform_id="a",le="+Inf"} 3
+test_transform_duration_seconds_sum{transform_id="a"} 2.55
+test_transform_duration_seconds_count{transform_id="a"} 3
+`,
+		},
+		{
+			"label escaping",
+			nil,
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"Transform.ID": "a\"b\\c\nd"}},
+			},
+			`# TYPE substation_messages_count_total counter
+substation_messages_count_total{transform_id="a\"b\\c\nd"} 1
+`,
+		},
+		{
+			"cardinality limit",
+			map[string]interface{}{
+				"max_attribute_values": 2,
+			},
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "b"}},
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "c"}},
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "d"}},
+				// Values that are below the limit are not replaced.
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+			},
+			`# TYPE substation_messages_count_total counter
+substation_messages_count_total{transform_id="a"} 2
+substation_messages_count_total{transform_id="b"} 1
+substation_messages_count_total{transform_id="other"} 2
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := map[string]interface{}{
+				"address": "127.0.0.1:0",
+			}
+			for k, v := range test.settings {
+				settings[k] = v
+			}
+
+			m, err := newPrometheus(ctx, config.Config{Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Generators with the same address share a registry, so each
+			// test uses its own.
+			m.registry = newPromRegistry()
+
+			for _, d := range test.data {
+				if err := m.Generate(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			srv := httptest.NewServer(m.registry)
+			defer srv.Close()
+
+			resp, err := http.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, body)
+			}
+
+			if ct := resp.Header.Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+				t.Errorf("unexpected content type %s", ct)
+			}
+		})
+	}
+}
+
+func TestPrometheusUnsupportedValue(t *testing.T) {
+	m, err := newPrometheus(context.TODO(), config.Config{Settings: map[string]interface{}{"address": "127.0.0.1:0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Generate(context.TODO(), Data{Name: "MessagesCount", Value: "a"}); err == nil {
+		t.Error("expected error")
+	}
+}
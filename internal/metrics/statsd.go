@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+// statsdTagReplacer replaces characters that are reserved by the DogStatsD protocol.
+var statsdTagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+type statsdConfig struct {
+	// Address is the address of the StatsD server. UDP addresses use the host:port
+	// format (e.g., 127.0.0.1:8125) and Unix domain socket addresses use the
+	// unix:// scheme (e.g., unix:///var/run/datadog/dsd.socket).
+	//
+	// This is optional and defaults to "127.0.0.1:8125".
+	Address string `json:"address"`
+	// Namespace is prefixed to the name of every metric.
+	//
+	// This is optional and defaults to "substation".
+	Namespace string `json:"namespace"`
+	// FlushInterval is the amount of time that metrics are aggregated before they
+	// are sent to the server. Counters with the same name and tags are summed and
+	// timings are sent together in as few packets as possible. Aggregated metrics
+	// are flushed and metrics are sent immediately after the context used to create
+	// the generator is done or the generator is closed.
+	//
+	// This is optional and defaults to sending metrics immediately.
+	FlushInterval string `json:"flush_interval"`
+	// MaxPacketSize is the maximum size of a packet in bytes.
+	//
+	// This is optional and defaults to 1432 for UDP and 8192 for Unix domain sockets.
+	MaxPacketSize int `json:"max_packet_size"`
+}
+
+// statsd sends metrics to a StatsD server using the DogStatsD protocol. Attributes
+// are sent as tags (e.g., |#key:value). Read more about the protocol here:
+// https://docs.datadoghq.com/developers/dogstatsd/datagram_shell/.
+//
+// Metrics are converted based on the type of their value:
+//
+// - Durations (time.Duration) are sent as timings in milliseconds.
+//
+// - All other numbers are sent as counters.
+type statsd struct {
+	conf statsdConfig
+
+	mu   sync.Mutex
+	conn net.Conn
+	// aggregate is true while metrics are periodically flushed.
+	aggregate bool
+	// counters and timings contain metrics that are waiting to be flushed.
+	counters map[string]float64
+	timings  []string
+
+	// done stops the flush goroutine.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newStatsD(ctx context.Context, cfg config.Config) (*statsd, error) {
+	conf := statsdConfig{}
+	if err := iconfig.Decode(cfg.Settings, &conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Address == "" {
+		conf.Address = "127.0.0.1:8125"
+	}
+
+	if conf.Namespace == "" {
+		conf.Namespace = "substation"
+	}
+
+	network, address := "udp", conf.Address
+	if strings.HasPrefix(address, "unix://") {
+		network, address = "unixgram", strings.TrimPrefix(address, "unix://")
+	}
+
+	if conf.MaxPacketSize == 0 {
+		conf.MaxPacketSize = 1432
+		if network == "unixgram" {
+			conf.MaxPacketSize = 8192
+		}
+	}
+
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("metrics statsd: %v", err)
+	}
+
+	m := &statsd{
+		conf:     conf,
+		conn:     conn,
+		counters: make(map[string]float64),
+		done:     make(chan struct{}),
+	}
+
+	if conf.FlushInterval != "" {
+		dur, err := time.ParseDuration(conf.FlushInterval)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("metrics statsd: flush_interval: %v", err)
+		}
+
+		m.aggregate = true
+		go m.run(ctx, dur)
+	}
+
+	return m, nil
+}
+
+func (m *statsd) Generate(ctx context.Context, data Data) error {
+	name := m.name(data.Name)
+	if name == "" {
+		return nil
+	}
+
+	tags := m.tags(data.Attributes)
+
+	switch v := data.Value.(type) {
+	case time.Duration:
+		ms := strconv.FormatFloat(float64(v)/float64(time.Millisecond), 'f', -1, 64)
+		line := fmt.Sprintf("%s:%s|ms%s", name, ms, tags)
+
+		m.mu.Lock()
+		if !m.aggregate {
+			m.mu.Unlock()
+			return m.send([]string{line})
+		}
+
+		m.timings = append(m.timings, line)
+		m.mu.Unlock()
+	default:
+		f, ok := toFloat64(v)
+		if !ok {
+			return fmt.Errorf("metrics statsd: %s: unsupported value type %T", data.Name, data.Value)
+		}
+
+		m.mu.Lock()
+		if !m.aggregate {
+			m.mu.Unlock()
+			return m.send([]string{fmt.Sprintf("%s:%s|c%s", name, strconv.FormatFloat(f, 'f', -1, 64), tags)})
+		}
+
+		// Counters are aggregated by their name and tags, which are both
+		// stored in the key.
+		m.counters[fmt.Sprintf("%s|%s", name, tags)] += f
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+// run periodically flushes aggregated metrics until the context is done or
+// the generator is closed. Metrics that are generated after it stops are sent
+// immediately.
+func (m *statsd) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = m.stop()
+			return
+		case <-m.done:
+			return
+		case <-ticker.C:
+			_ = m.flush()
+		}
+	}
+}
+
+// Flush sends every aggregated metric to the server.
+func (m *statsd) Flush(_ context.Context) error {
+	return m.flush()
+}
+
+// Close stops aggregating metrics, sends every aggregated metric to the server,
+// and closes the connection.
+func (m *statsd) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		err = m.stop()
+
+		if cerr := m.conn.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("metrics statsd: %v", cerr)
+		}
+	})
+
+	return err
+}
+
+// stop disables aggregation and flushes the aggregated metrics.
+func (m *statsd) stop() error {
+	m.mu.Lock()
+	m.aggregate = false
+	m.mu.Unlock()
+
+	return m.flush()
+}
+
+// flush sends every aggregated metric to the server.
+func (m *statsd) flush() error {
+	m.mu.Lock()
+	counters, timings := m.counters, m.timings
+	m.counters, m.timings = make(map[string]float64), nil
+	m.mu.Unlock()
+
+	lines := make([]string, 0, len(counters)+len(timings))
+	for k, v := range counters {
+		name, tags, _ := strings.Cut(k, "|")
+		lines = append(lines, fmt.Sprintf("%s:%s|c%s", name, strconv.FormatFloat(v, 'f', -1, 64), tags))
+	}
+	sort.Strings(lines)
+
+	lines = append(lines, timings...)
+	return m.send(lines)
+}
+
+// send writes metrics to the server. Multiple metrics are combined into
+// newline delimited packets that are no larger than the max packet size.
+func (m *statsd) send(lines []string) error {
+	var b strings.Builder
+	for _, l := range lines {
+		if b.Len() > 0 && b.Len()+len(l)+1 > m.conf.MaxPacketSize {
+			if _, err := m.conn.Write([]byte(b.String())); err != nil {
+				return fmt.Errorf("metrics statsd: %v", err)
+			}
+
+			b.Reset()
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+
+		b.WriteString(l)
+	}
+
+	if b.Len() == 0 {
+		return nil
+	}
+
+	if _, err := m.conn.Write([]byte(b.String())); err != nil {
+		return fmt.Errorf("metrics statsd: %v", err)
+	}
+
+	return nil
+}
+
+// name converts the metric name to snake case and adds the namespace
+// (e.g., substation.messages_count).
+func (m *statsd) name(name string) string {
+	n := snakeCase(name)
+	if n == "" || m.conf.Namespace == "" {
+		return n
+	}
+
+	return fmt.Sprintf("%s.%s", m.conf.Namespace, n)
+}
+
+// tags converts attributes to DogStatsD tags (e.g., |#key:value,key:value).
+func (m *statsd) tags(attr map[string]string) string {
+	if len(attr) == 0 {
+		return ""
+	}
+
+	tags := make([]string, 0, len(attr))
+	for k, v := range attr {
+		tags = append(tags, fmt.Sprintf("%s:%s", snakeCase(k), statsdTagReplacer.Replace(v)))
+	}
+	sort.Strings(tags)
+
+	return "|#" + strings.Join(tags, ",")
+}
@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+// statsdTestListener returns a UDP listener that receives metrics.
+func statsdTestListener(t *testing.T) net.PacketConn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// statsdTestRead returns every packet received by the listener.
+func statsdTestRead(t *testing.T, conn net.PacketConn, count int) []string {
+	t.Helper()
+
+	var packets []string
+	buf := make([]byte, 8192)
+	for i := 0; i < count; i++ {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		packets = append(packets, string(buf[:n]))
+	}
+
+	// No more packets are expected.
+	if err := conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _, err := conn.ReadFrom(buf); err == nil {
+		t.Errorf("unexpected packet %s", buf[:n])
+	}
+
+	return packets
+}
+
+func TestStatsD(t *testing.T) {
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		data     []Data
+		expected []string
+	}{
+		{
+			"counter",
+			nil,
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a", "TransformType": "b"}},
+				{Name: "BytesCount", Value: 1.5},
+			},
+			[]string{
+				"substation.messages_count:1|c|#transform_id:a,transform_type:b",
+				"substation.bytes_count:1.5|c",
+			},
+		},
+		{
+			"timing",
+			map[string]interface{}{"namespace": "test"},
+			[]Data{
+				{Name: "TransformDuration", Value: 1500 * time.Microsecond, Attributes: map[string]string{"TransformID": "a"}},
+			},
+			[]string{
+				"test.transform_duration:1.5|ms|#transform_id:a",
+			},
+		},
+		{
+			"reserved characters",
+			nil,
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a|b,c#d\ne"}},
+			},
+			[]string{
+				"substation.messages_count:1|c|#transform_id:a_b_c_d_e",
+			},
+		},
+		{
+			"aggregation",
+			map[string]interface{}{"flush_interval": "1h"},
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 2, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 3, Attributes: map[string]string{"TransformID": "b"}},
+				{Name: "TransformDuration", Value: time.Millisecond},
+				{Name: "TransformDuration", Value: 2 * time.Millisecond},
+			},
+			[]string{
+				"substation.messages_count:3|c|#transform_id:a\n" +
+					"substation.messages_count:3|c|#transform_id:b\n" +
+					"substation.transform_duration:1|ms\n" +
+					"substation.transform_duration:2|ms",
+			},
+		},
+		{
+			"max packet size",
+			map[string]interface{}{"flush_interval": "1h", "max_packet_size": 64},
+			[]Data{
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "a"}},
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "b"}},
+				{Name: "MessagesCount", Value: 1, Attributes: map[string]string{"TransformID": "c"}},
+			},
+			[]string{
+				"substation.messages_count:1|c|#transform_id:a",
+				"substation.messages_count:1|c|#transform_id:b",
+				"substation.messages_count:1|c|#transform_id:c",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := statsdTestListener(t)
+
+			settings := map[string]interface{}{
+				"address": conn.LocalAddr().String(),
+			}
+			for k, v := range test.settings {
+				settings[k] = v
+			}
+
+			m, err := newStatsD(ctx, config.Config{Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, d := range test.data {
+				if err := m.Generate(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Aggregated metrics are flushed when the generator is closed.
+			if err := m.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			packets := statsdTestRead(t, conn, len(test.expected))
+			for i, p := range packets {
+				if p != test.expected[i] {
+					t.Errorf("expected %q, got %q", test.expected[i], p)
+				}
+			}
+		})
+	}
+}
+
+func TestStatsDFlushInterval(t *testing.T) {
+	conn := statsdTestListener(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m, err := newStatsD(ctx, config.Config{Settings: map[string]interface{}{
+		"address":        conn.LocalAddr().String(),
+		"flush_interval": "10ms",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := m.Generate(ctx, Data{Name: "MessagesCount", Value: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The metrics are flushed by the goroutine.
+	if p := statsdTestRead(t, conn, 1); p[0] != "substation.messages_count:2|c" {
+		t.Errorf("expected substation.messages_count:2|c, got %q", p[0])
+	}
+
+	if err := m.Generate(ctx, Data{Name: "MessagesCount", Value: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Aggregated metrics are flushed when the context is done, and
+	// metrics are sent immediately after that.
+	cancel()
+
+	if p := statsdTestRead(t, conn, 1); p[0] != "substation.messages_count:1|c" {
+		t.Errorf("expected substation.messages_count:1|c, got %q", p[0])
+	}
+
+	if err := m.Generate(ctx, Data{Name: "MessagesCount", Value: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := statsdTestRead(t, conn, 1); p[0] != "substation.messages_count:3|c" {
+		t.Errorf("expected substation.messages_count:3|c, got %q", p[0])
+	}
+}
@@ -3,8 +3,10 @@ package secrets
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 
 	"github.com/brexhq/substation/v2/config"
@@ -38,6 +40,10 @@ type awsSecretsManager struct {
 	conf   awsSecretsManagerConfig
 	client *secretsmanager.Client
 
+	// mu protects the TTL, which changes during retrieval.
+	mu sync.Mutex
+	// dur is the amount of time that the secret is cached.
+	dur time.Duration
 	ttl int64
 }
 
@@ -63,6 +69,7 @@ func newAWSSecretsManager(ctx context.Context, cfg config.Config) (*awsSecretsMa
 
 	c := &awsSecretsManager{
 		conf: conf,
+		dur:  dur,
 		ttl:  time.Now().Add(dur).Unix(),
 	}
 
@@ -85,15 +92,19 @@ func (c *awsSecretsManager) Retrieve(ctx context.Context) error {
 		return fmt.Errorf("secrets: aws_secrets_manager: %v", err)
 	}
 
-	// SetWithTTL isn't used here because the TTL is managed by
-	// transform/utility_secret.go.
-	if err := cache.Set(ctx, c.conf.ID, v.SecretString); err != nil {
-		return fmt.Errorf("secrets: aws_secrets_manager: id %s: %v", c.conf.ID, err)
-	}
+	// The TTL is managed by transform/utility_secret.go.
+	cache.Set(c.conf.ID, aws.ToString(v.SecretString))
+
+	c.mu.Lock()
+	c.ttl = time.Now().Add(c.dur).Unix()
+	c.mu.Unlock()
 
 	return nil
 }
 
 func (c *awsSecretsManager) Expired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return time.Now().Unix() >= c.ttl
 }
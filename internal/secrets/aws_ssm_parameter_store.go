@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type awsSSMParameterStoreConfig struct {
+	ID        string      `json:"id"`
+	TTLOffset string      `json:"ttl_offset"`
+	AWS       iconfig.AWS `json:"aws"`
+}
+
+func (c *awsSSMParameterStoreConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *awsSSMParameterStoreConfig) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.AWS.ARN == "" {
+		return fmt.Errorf("aws.arn: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+type awsSSMParameterStore struct {
+	conf   awsSSMParameterStoreConfig
+	client *ssm.Client
+
+	// mu protects the TTL, which changes during retrieval.
+	mu sync.Mutex
+	// dur is the amount of time that the secret is cached.
+	dur time.Duration
+	ttl int64
+}
+
+func newAWSSSMParameterStore(ctx context.Context, cfg config.Config) (*awsSSMParameterStore, error) {
+	conf := awsSSMParameterStoreConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("secrets: aws_ssm_parameter_store: %v", err)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("secrets: aws_ssm_parameter_store: %v", err)
+	}
+
+	ttl := conf.TTLOffset
+	if ttl == "" {
+		ttl = defaultTTL
+	}
+
+	dur, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: aws_ssm_parameter_store: %v", err)
+	}
+
+	c := &awsSSMParameterStore{
+		conf: conf,
+		dur:  dur,
+		ttl:  time.Now().Add(dur).Unix(),
+	}
+
+	awsCfg, err := iconfig.NewAWS(ctx, conf.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: aws_ssm_parameter_store: %v", err)
+	}
+
+	c.client = ssm.NewFromConfig(awsCfg)
+
+	return c, nil
+}
+
+func (c *awsSSMParameterStore) Retrieve(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+
+	// SecureString parameters are decrypted using the KMS key that was used to
+	// encrypt them. Decryption has no effect on String and StringList parameters.
+	v, err := c.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &c.conf.AWS.ARN,
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("secrets: aws_ssm_parameter_store: %v", err)
+	}
+
+	// The TTL is managed by transform/utility_secret.go.
+	cache.Set(c.conf.ID, aws.ToString(v.Parameter.Value))
+
+	c.mu.Lock()
+	c.ttl = time.Now().Add(c.dur).Unix()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *awsSSMParameterStore) Expired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Now().Unix() >= c.ttl
+}
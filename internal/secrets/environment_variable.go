@@ -67,11 +67,8 @@ func newEnvironmentVariable(_ context.Context, cfg config.Config) (*env, error)
 
 func (c *env) Retrieve(ctx context.Context) error {
 	if v, ok := os.LookupEnv(c.conf.Name); ok {
-		// SetWithTTL isn't used here because the TTL is managed by
-		// transform/utility_secret.go.
-		if err := cache.Set(ctx, c.conf.ID, v); err != nil {
-			return fmt.Errorf("secrets: environment_variable: id %s: %v", c.conf.ID, err)
-		}
+		// The TTL is managed by transform/utility_secret.go.
+		cache.Set(c.conf.ID, v)
 	}
 
 	return nil
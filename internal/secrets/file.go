@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type fileConfig struct {
+	ID string `json:"id"`
+	// Path is the location of the file on local disk. This is commonly used with
+	// secrets that are mounted into containers, such as Kubernetes secrets
+	// (e.g., /var/run/secrets/my-secret) or Docker secrets (e.g., /run/secrets/my-secret).
+	Path      string `json:"path"`
+	TTLOffset string `json:"ttl_offset"`
+}
+
+func (c *fileConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *fileConfig) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Path == "" {
+		return fmt.Errorf("path: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+type file struct {
+	conf fileConfig
+
+	// mu protects the TTL, which changes during retrieval.
+	mu sync.Mutex
+	// dur is the amount of time that the secret is cached.
+	dur time.Duration
+	ttl int64
+}
+
+func newFile(_ context.Context, cfg config.Config) (*file, error) {
+	conf := fileConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("secrets: file: %v", err)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("secrets: file: %v", err)
+	}
+
+	ttl := conf.TTLOffset
+	if ttl == "" {
+		ttl = defaultTTL
+	}
+
+	dur, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: file: %v", err)
+	}
+
+	return &file{
+		conf: conf,
+		dur:  dur,
+		ttl:  time.Now().Add(dur).Unix(),
+	}, nil
+}
+
+func (c *file) Retrieve(ctx context.Context) error {
+	b, err := os.ReadFile(c.conf.Path)
+	if err != nil {
+		return fmt.Errorf("secrets: file: %v", err)
+	}
+
+	// Files that are created by text editors and tools like echo often
+	// end with a newline that is not part of the secret.
+	v := strings.TrimRight(string(b), "\r\n")
+
+	// The TTL is managed by transform/utility_secret.go.
+	cache.Set(c.conf.ID, v)
+
+	c.mu.Lock()
+	c.ttl = time.Now().Add(c.dur).Unix()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *file) Expired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Now().Unix() >= c.ttl
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+)
+
+type gcpSecretManagerConfig struct {
+	ID        string `json:"id"`
+	TTLOffset string `json:"ttl_offset"`
+	// GCP.Resource is the name of the secret (projects/my-project/secrets/my-secret)
+	// or the secret version (projects/my-project/secrets/my-secret/versions/1). If no
+	// version is provided, then the latest version is retrieved.
+	GCP iconfig.GCP `json:"gcp"`
+}
+
+func (c *gcpSecretManagerConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *gcpSecretManagerConfig) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.GCP.Resource == "" {
+		return fmt.Errorf("gcp.resource: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+type gcpSecretManager struct {
+	conf   gcpSecretManagerConfig
+	client *secretmanager.Client
+
+	name string
+
+	// mu protects the TTL, which changes during retrieval.
+	mu sync.Mutex
+	// dur is the amount of time that the secret is cached.
+	dur time.Duration
+	ttl int64
+}
+
+func newGCPSecretManager(ctx context.Context, cfg config.Config) (*gcpSecretManager, error) {
+	conf := gcpSecretManagerConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("secrets: gcp_secret_manager: %v", err)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("secrets: gcp_secret_manager: %v", err)
+	}
+
+	ttl := conf.TTLOffset
+	if ttl == "" {
+		ttl = defaultTTL
+	}
+
+	dur, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: gcp_secret_manager: %v", err)
+	}
+
+	c := &gcpSecretManager{
+		conf: conf,
+		name: conf.GCP.Resource,
+		dur:  dur,
+		ttl:  time.Now().Add(dur).Unix(),
+	}
+
+	if !strings.Contains(c.name, "/versions/") {
+		c.name = fmt.Sprintf("%s/versions/latest", strings.TrimSuffix(c.name, "/"))
+	}
+
+	client, err := secretmanager.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: gcp_secret_manager: %v", err)
+	}
+
+	c.client = client
+
+	return c, nil
+}
+
+func (c *gcpSecretManager) Retrieve(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+	v, err := c.client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{
+		Name: c.name,
+	})
+	if err != nil {
+		return fmt.Errorf("secrets: gcp_secret_manager: %v", err)
+	}
+
+	// The TTL is managed by transform/utility_secret.go.
+	cache.Set(c.conf.ID, string(v.GetPayload().GetData()))
+
+	c.mu.Lock()
+	c.ttl = time.Now().Add(c.dur).Unix()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *gcpSecretManager) Expired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Now().Unix() >= c.ttl
+}
@@ -2,15 +2,21 @@
 package secrets
 
 import (
+	"container/list"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+
+	"github.com/tidwall/gjson"
 
 	"github.com/brexhq/substation/v2/config"
 
 	iconfig "github.com/brexhq/substation/v2/internal/config"
-	"github.com/brexhq/substation/v2/internal/kv"
 )
 
 var (
@@ -19,10 +25,21 @@ var (
 	interpRe = regexp.MustCompile(`\${(SECRET:[^}]+)}`)
 	// errNoSecret is returned when no secrets are found in the cache.
 	errNoSecret = fmt.Errorf("secrets: no secret found")
-	// KV store is used as a secrets cache
-	cache kv.Storer
+	// errNoSecretPath is returned when a path does not exist in a secret.
+	errNoSecretPath = fmt.Errorf("secrets: no value found at path")
+	// cache stores secrets in memory. This cannot use a KV store because
+	// internal/kv imports this package to interpolate credentials (e.g., the
+	// Redis password), which would be an import cycle.
+	cache = secretsCache{
+		capacity: 1000,
+		items:    make(map[string]*list.Element),
+		values:   make(map[string]struct{}),
+	}
 )
 
+// redacted replaces secret values in strings that are returned by Redact.
+const redacted = "[REDACTED]"
+
 // defaultTTL enforces a 15 minute rotation for all secrets stored in memory.
 const defaultTTL = "15m"
 
@@ -35,8 +52,16 @@ func New(ctx context.Context, cfg config.Config) (Retriever, error) {
 	switch cfg.Type {
 	case "aws_secrets_manager":
 		return newAWSSecretsManager(ctx, cfg)
+	case "aws_ssm_parameter_store":
+		return newAWSSSMParameterStore(ctx, cfg)
 	case "environment_variable":
 		return newEnvironmentVariable(ctx, cfg)
+	case "file":
+		return newFile(ctx, cfg)
+	case "gcp_secret_manager":
+		return newGCPSecretManager(ctx, cfg)
+	case "vault":
+		return newVault(ctx, cfg)
 	default:
 		return nil, fmt.Errorf("secrets: new: type %q settings %+v: %v", cfg.Type, cfg.Settings, iconfig.ErrInvalidFactoryInput)
 	}
@@ -55,6 +80,12 @@ func New(ctx context.Context, cfg config.Config) (Retriever, error) {
 // "/path/to/${SECRET:FOO}/${SECRET:BAZ}", then the interpolated string
 // is "/path/to/BAR/QUX".
 //
+// If the secret is a JSON object, then a value can be selected from the
+// secret using a path after the secret name. For example, if the secret
+// FOO is {"user":"BAR","password":"BAZ"}, then "${SECRET:FOO.password}"
+// is interpolated as "BAZ". Paths use the same syntax as values in
+// messages (https://github.com/tidwall/gjson/blob/master/SYNTAX.md).
+//
 // If more than one interpolation function is applied to a string (e.g., non-secrets
 // capture groups), then this function must be called first.
 func Interpolate(ctx context.Context, s string) (string, error) {
@@ -69,39 +100,206 @@ func Interpolate(ctx context.Context, s string) (string, error) {
 		}
 
 		secretName := strings.ReplaceAll(m[len(m)-1], "SECRET:", "")
-		secret, err := cache.Get(ctx, secretName)
+		secret, err := lookup(secretName)
 		if err != nil {
 			return "", err
 		}
 
-		if secret == nil {
-			return "", errNoSecret
-		}
-
 		// Replaces each substring with a secret. If the secret is
 		// BAR and the string was "/path/to/secret/${SECRET:FOO}",
 		// then the interpolated  string output is "/path/to/secret/BAR".
 		old := fmt.Sprintf("${%s}", m[len(m)-1])
-		s = strings.Replace(s, old, secret.(string), 1)
+		s = strings.Replace(s, old, secret, 1)
 	}
 
 	return s, nil
 }
 
-func init() {
-	kv, err := kv.New(config.Config{
-		Type: "memory",
-		Settings: map[string]interface{}{
-			"capacity": 1000,
-		},
-	})
-	if err != nil {
-		panic(err)
+// lookup returns a secret from the cache. If no secret matches the name, then
+// the name is split into a secret name and a path (NAME.path) and the value at
+// the path is returned.
+func lookup(name string) (string, error) {
+	if secret, ok := cache.Get(name); ok {
+		return secret, nil
+	}
+
+	id, path, ok := strings.Cut(name, ".")
+	if !ok {
+		return "", errNoSecret
+	}
+
+	secret, ok := cache.Get(id)
+	if !ok {
+		return "", errNoSecret
+	}
+
+	res := gjson.Get(secret, path)
+	if !res.Exists() {
+		return "", errNoSecretPath
+	}
+
+	// Values selected from a secret are also secrets.
+	v := res.String()
+	cache.AddValue(v)
+
+	return v, nil
+}
+
+// Redact replaces every secret value in a string with "[REDACTED]". This
+// includes every secret that was retrieved and every value that was selected
+// from a secret during interpolation, including values that were rotated.
+//
+// Values are also replaced if they are JSON or URL encoded, which makes this
+// safe to use with JSON configurations and errors that contain URLs.
+func Redact(s string) string {
+	r := cache.Replacer()
+	if r == nil {
+		return s
+	}
+
+	return r.Replace(s)
+}
+
+// RedactError returns an error with every secret value replaced by "[REDACTED]".
+// If the error does not contain any secrets, then the original error is returned.
+// The returned error wraps the original error, so it can be inspected with
+// errors.Is and errors.As.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	s := err.Error()
+	if r := Redact(s); r != s {
+		return &redactedError{msg: r, err: err}
+	}
+
+	return err
+}
+
+// redactedError is an error with secret values removed from its message.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// secretsCache is a concurrency-safe, in-memory store of secrets. The store
+// uses least recently used (LRU) eviction when it exceeds its capacity.
+type secretsCache struct {
+	mu       sync.RWMutex
+	capacity int
+	lru      list.List
+	items    map[string]*list.Element
+
+	// values contains every secret value that should be redacted. Values
+	// are never removed, so secrets remain redacted after rotation.
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+type secretsCacheElement struct {
+	key   string
+	value string
+}
+
+func (c *secretsCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	node, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	// Resetting the position of the node prevents recently accessed items from being evicted.
+	c.lru.MoveToFront(node)
+	return node.Value.(secretsCacheElement).value, true
+}
+
+func (c *secretsCache) Set(key, val string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addValue(val)
+
+	if node, ok := c.items[key]; ok {
+		c.lru.MoveToFront(node)
+		node.Value = secretsCacheElement{key, val}
+
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(secretsCacheElement{key, val})
+	if c.lru.Len() > c.capacity {
+		node := c.lru.Back()
+
+		c.lru.Remove(node)
+		delete(c.items, node.Value.(secretsCacheElement).key)
+	}
+}
+
+func (c *secretsCache) AddValue(val string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.addValue(val)
+}
+
+func (c *secretsCache) Replacer() *strings.Replacer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.replacer
+}
+
+// addValue must be called while holding the lock.
+func (c *secretsCache) addValue(val string) {
+	if val == "" {
+		return
+	}
+
+	if _, ok := c.values[val]; ok {
+		return
+	}
+
+	c.values[val] = struct{}{}
+
+	// Secrets are commonly stored in JSON (e.g., String methods) and URLs
+	// (e.g., errors from HTTP clients), so the encoded values are also redacted.
+	if b, err := json.Marshal(val); err == nil {
+		c.values[string(b[1:len(b)-1])] = struct{}{}
+	}
+
+	c.values[url.QueryEscape(val)] = struct{}{}
+	c.values[url.PathEscape(val)] = struct{}{}
+
+	// Longer values are replaced first so that a value that contains
+	// another value is fully redacted.
+	vals := make([]string, 0, len(c.values))
+	for v := range c.values {
+		vals = append(vals, v)
 	}
 
-	if err := kv.Setup(context.TODO()); err != nil {
-		panic(err)
+	sort.Slice(vals, func(i, j int) bool {
+		if len(vals[i]) != len(vals[j]) {
+			return len(vals[i]) > len(vals[j])
+		}
+
+		return vals[i] < vals[j]
+	})
+
+	oldnew := make([]string, 0, len(vals)*2)
+	for _, v := range vals {
+		oldnew = append(oldnew, v, redacted)
 	}
 
-	cache = kv
+	c.replacer = strings.NewReplacer(oldnew...)
 }
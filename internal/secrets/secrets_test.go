@@ -1,8 +1,14 @@
 package secrets
 
 import (
+	"container/list"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/brexhq/substation/v2/config"
 )
@@ -42,6 +48,133 @@ func TestCollect(t *testing.T) {
 	}
 }
 
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("baz\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	cfg := config.Config{
+		Type: "file",
+		Settings: map[string]interface{}{
+			"id":   "file",
+			"path": path,
+		},
+	}
+
+	ret, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ret.Retrieve(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	interp, err := Interpolate(ctx, "${SECRET:file}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if interp != "baz" {
+		t.Fatalf("unexpected interpolation: %s", interp)
+	}
+}
+
+func TestFileExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("baz"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	ret, err := newFile(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"id":         "file_expired",
+			"path":       path,
+			"ttl_offset": "1h",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.Expired() {
+		t.Fatal("expected secret to not be expired")
+	}
+
+	// The secret expires when the TTL is reached.
+	ret.ttl = time.Now().Add(-time.Second).Unix()
+	if !ret.Expired() {
+		t.Fatal("expected secret to be expired")
+	}
+
+	// The TTL is reset after each retrieval.
+	if err := ret.Retrieve(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret.Expired() {
+		t.Error("expected secret to not be expired after retrieval")
+	}
+}
+
+func TestInterpolatePath(t *testing.T) {
+	t.Setenv("CREDENTIALS", `{"user":"foo","password":"p@ss&word"}`)
+
+	ctx := context.Background()
+
+	cfg := config.Config{
+		Type: "environment_variable",
+		Settings: map[string]interface{}{
+			"id":   "credentials",
+			"name": "CREDENTIALS",
+		},
+	}
+
+	ret, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ret.Retrieve(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	interp, err := Interpolate(ctx, "https://${SECRET:credentials.user}:${SECRET:credentials.password}@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if interp != "https://foo:p@ss&word@example.com" {
+		t.Fatalf("unexpected interpolation: %s", interp)
+	}
+
+	if _, err := Interpolate(ctx, "${SECRET:credentials.token}"); err == nil {
+		t.Fatal("expected error for missing path")
+	}
+
+	// Selected values, encoded values, and the entire secret are redacted.
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"password: p@ss&word", "password: [REDACTED]"},
+		{`{"password":"p@ss\u0026word"}`, `{"password":"[REDACTED]"}`},
+		{"https://example.com?password=p%40ss%26word", "https://example.com?password=[REDACTED]"},
+		{`secret: {"user":"foo","password":"p@ss&word"}`, "secret: [REDACTED]"},
+	}
+
+	for _, test := range tests {
+		if r := Redact(test.in); r != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, r)
+		}
+	}
+}
+
 // TODO (akline@brex.com): Interpolate panics in certain situations so this needs some work
 // func FuzzInterpolate(f *testing.F) {
 // 	// Seed the fuzzer with initial test cases
@@ -81,3 +214,128 @@ func TestCollect(t *testing.T) {
 // 		_ = interp
 // 	})
 // }
+
+func TestSecretsCacheCapacity(t *testing.T) {
+	c := secretsCache{
+		capacity: 2,
+		items:    make(map[string]*list.Element),
+		values:   make(map[string]struct{}),
+	}
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+
+	// Accessing "a" makes "b" the least recently used secret.
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a")
+	}
+
+	c.Set("c", "3")
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("expected %s", key)
+		}
+	}
+
+	// Evicted secrets remain redacted.
+	if r := c.Replacer().Replace("2"); r != redacted {
+		t.Errorf("expected %s, got %s", redacted, r)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	t.Setenv("REDACT", "p@ss&word")
+
+	ctx := context.Background()
+
+	cfg := config.Config{
+		Type: "environment_variable",
+		Settings: map[string]interface{}{
+			"id":   "redact",
+			"name": "REDACT",
+		},
+	}
+
+	ret, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ret.Retrieve(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Encoded values are also redacted.
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"password: p@ss&word", "password: [REDACTED]"},
+		{`{"password":"p@ss\u0026word"}`, `{"password":"[REDACTED]"}`},
+		{"https://example.com?password=p%40ss%26word", "https://example.com?password=[REDACTED]"},
+		{"password: none", "password: none"},
+	}
+
+	for _, test := range tests {
+		if r := Redact(test.in); r != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, r)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	t.Setenv("REDACT_ERROR", "hunter2")
+
+	ctx := context.Background()
+
+	cfg := config.Config{
+		Type: "environment_variable",
+		Settings: map[string]interface{}{
+			"id":   "redact_error",
+			"name": "REDACT_ERROR",
+		},
+	}
+
+	ret, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ret.Retrieve(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	errBase := errors.New("base")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"secret", fmt.Errorf("password hunter2: %w", errBase), "password [REDACTED]: base"},
+		{"no secret", fmt.Errorf("password: %w", errBase), "password: base"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := RedactError(test.err)
+			if err.Error() != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, err)
+			}
+
+			// The original error is preserved.
+			if !errors.Is(err, errBase) {
+				t.Errorf("expected error to wrap %v", errBase)
+			}
+		})
+	}
+
+	if RedactError(nil) != nil {
+		t.Error("expected nil")
+	}
+}
@@ -0,0 +1,437 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	ihttp "github.com/brexhq/substation/v2/internal/http"
+)
+
+// errVaultFieldNotFound is returned when the field is not in the secret.
+var errVaultFieldNotFound = fmt.Errorf("field not found")
+
+type vaultAuthConfig struct {
+	// Type is the authentication method used to get a Vault token. Must be one of:
+	//
+	// - token: uses a static token
+	//
+	// - approle: logs in with a role ID and secret ID
+	//
+	// - kubernetes: logs in with a Kubernetes service account token
+	//
+	// This is optional and defaults to token.
+	Type string `json:"type"`
+	// Mount is the path where the auth method is enabled.
+	//
+	// This is optional and defaults to the name of the auth method (approle or kubernetes).
+	Mount string `json:"mount"`
+	// Token is used by the token auth method.
+	//
+	// This is optional and defaults to the VAULT_TOKEN environment variable.
+	Token string `json:"token"`
+	// RoleID and SecretID are used by the approle auth method.
+	RoleID   string `json:"role_id"`
+	SecretID string `json:"secret_id"`
+	// Role is used by the kubernetes auth method.
+	Role string `json:"role"`
+	// TokenPath is the location of the service account token used by the kubernetes
+	// auth method.
+	//
+	// This is optional and defaults to /var/run/secrets/kubernetes.io/serviceaccount/token.
+	TokenPath string `json:"token_path"`
+}
+
+type vaultConfig struct {
+	ID        string `json:"id"`
+	TTLOffset string `json:"ttl_offset"`
+	// Address is the URL of the Vault server.
+	//
+	// This is optional and defaults to the VAULT_ADDR environment variable.
+	Address string `json:"address"`
+	// Namespace is the Vault Enterprise namespace that contains the secret.
+	//
+	// This is optional and defaults to the VAULT_NAMESPACE environment variable.
+	Namespace string `json:"namespace"`
+	// Mount is the path where the KV v2 secrets engine is enabled.
+	//
+	// This is optional and defaults to "secret".
+	Mount string `json:"mount"`
+	// Path is the location of the secret in the secrets engine (e.g., my-app/db).
+	Path string `json:"path"`
+	// Field is the key in the secret that is retrieved. If no field is provided, then
+	// the entire secret is retrieved as a JSON object.
+	//
+	// This is optional and defaults to the entire secret.
+	Field string `json:"field"`
+	// Version is the version of the secret that is retrieved.
+	//
+	// This is optional and defaults to the latest version.
+	Version int             `json:"version"`
+	Auth    vaultAuthConfig `json:"auth"`
+}
+
+func (c *vaultConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *vaultConfig) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("id: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Address == "" {
+		return fmt.Errorf("address: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Path == "" {
+		return fmt.Errorf("path: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	switch c.Auth.Type {
+	case "token":
+		if c.Auth.Token == "" {
+			return fmt.Errorf("auth.token: %v", iconfig.ErrMissingRequiredOption)
+		}
+	case "approle":
+		if c.Auth.RoleID == "" {
+			return fmt.Errorf("auth.role_id: %v", iconfig.ErrMissingRequiredOption)
+		}
+
+		if c.Auth.SecretID == "" {
+			return fmt.Errorf("auth.secret_id: %v", iconfig.ErrMissingRequiredOption)
+		}
+	case "kubernetes":
+		if c.Auth.Role == "" {
+			return fmt.Errorf("auth.role: %v", iconfig.ErrMissingRequiredOption)
+		}
+	default:
+		return fmt.Errorf("auth.type %s: %v", c.Auth.Type, iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// vaultResponse contains the fields that are used from Vault API responses.
+type vaultResponse struct {
+	Data json.RawMessage `json:"data"`
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// vault retrieves secrets from the HashiCorp Vault KV v2 secrets engine.
+//
+// Vault tokens are leased, so the token is renewed (or a new token is requested)
+// during retrieval if the lease expires before the next retrieval.
+type vault struct {
+	conf   vaultConfig
+	client ihttp.HTTP
+
+	// mu protects the token and TTLs, which change during retrieval.
+	mu sync.Mutex
+	// dur is the amount of time that the secret is cached.
+	dur time.Duration
+	ttl int64
+
+	token     string
+	renewable bool
+	// expiry is the time when the token lease expires. A zero value indicates that
+	// the token does not expire.
+	expiry time.Time
+}
+
+func newVault(_ context.Context, cfg config.Config) (*vault, error) {
+	conf := vaultConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("secrets: vault: %v", err)
+	}
+
+	if conf.Address == "" {
+		conf.Address = os.Getenv("VAULT_ADDR")
+	}
+
+	if conf.Namespace == "" {
+		conf.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	if conf.Mount == "" {
+		conf.Mount = "secret"
+	}
+
+	if conf.Auth.Type == "" {
+		conf.Auth.Type = "token"
+	}
+
+	if conf.Auth.Type == "token" && conf.Auth.Token == "" {
+		conf.Auth.Token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if conf.Auth.Mount == "" && conf.Auth.Type != "token" {
+		conf.Auth.Mount = conf.Auth.Type
+	}
+
+	if conf.Auth.TokenPath == "" {
+		conf.Auth.TokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("secrets: vault: %v", err)
+	}
+
+	ttl := conf.TTLOffset
+	if ttl == "" {
+		ttl = defaultTTL
+	}
+
+	dur, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("secrets: vault: %v", err)
+	}
+
+	c := &vault{
+		conf: conf,
+		dur:  dur,
+		ttl:  time.Now().Add(dur).Unix(),
+	}
+
+	c.client.Setup()
+
+	return c, nil
+}
+
+func (c *vault) Retrieve(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	if err := c.authenticate(ctx); err != nil {
+		return fmt.Errorf("secrets: vault: %v", err)
+	}
+
+	v, err := c.read(ctx)
+	if err != nil {
+		return fmt.Errorf("secrets: vault: %v", err)
+	}
+
+	// The TTL is managed by transform/utility_secret.go.
+	cache.Set(c.conf.ID, v)
+	c.ttl = time.Now().Add(c.dur).Unix()
+
+	return nil
+}
+
+func (c *vault) Expired() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Now().Unix() >= c.ttl
+}
+
+// authenticate ensures that the token is valid until the next retrieval. If the
+// token lease expires before then, then the token is renewed. If the token cannot
+// be renewed, then a new token is requested from the auth method.
+func (c *vault) authenticate(ctx context.Context) error {
+	if c.token != "" && (c.expiry.IsZero() || time.Now().Add(c.dur).Before(c.expiry)) {
+		return nil
+	}
+
+	if c.token != "" && c.renewable {
+		resp, err := c.do(ctx, http.MethodPost, "auth/token/renew-self", struct{}{})
+		if err == nil && resp.Auth != nil {
+			c.lease(resp.Auth.LeaseDuration, resp.Auth.Renewable)
+
+			// Renewal is limited by the max TTL of the token, so the
+			// lease may still expire before the next retrieval.
+			if c.expiry.IsZero() || time.Now().Add(c.dur).Before(c.expiry) {
+				return nil
+			}
+		}
+	}
+
+	return c.login(ctx)
+}
+
+// login requests a new token from the auth method.
+func (c *vault) login(ctx context.Context) error {
+	c.token = ""
+
+	var body map[string]string
+	switch c.conf.Auth.Type {
+	case "token":
+		c.token = c.conf.Auth.Token
+
+		// Static tokens are looked up to discover the lease.
+		resp, err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+		if err != nil {
+			c.token = ""
+			return err
+		}
+
+		var data struct {
+			TTL       int64 `json:"ttl"`
+			Renewable bool  `json:"renewable"`
+		}
+
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			c.token = ""
+			return err
+		}
+
+		c.lease(data.TTL, data.Renewable)
+		return nil
+	case "approle":
+		body = map[string]string{
+			"role_id":   c.conf.Auth.RoleID,
+			"secret_id": c.conf.Auth.SecretID,
+		}
+	case "kubernetes":
+		jwt, err := os.ReadFile(c.conf.Auth.TokenPath)
+		if err != nil {
+			return err
+		}
+
+		body = map[string]string{
+			"role": c.conf.Auth.Role,
+			"jwt":  strings.TrimSpace(string(jwt)),
+		}
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("auth/%s/login", c.conf.Auth.Mount), body)
+	if err != nil {
+		return err
+	}
+
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("auth/%s/login: no token in response", c.conf.Auth.Mount)
+	}
+
+	c.token = resp.Auth.ClientToken
+	c.lease(resp.Auth.LeaseDuration, resp.Auth.Renewable)
+
+	return nil
+}
+
+// lease updates the token expiry. A zero duration indicates that the token does
+// not expire.
+func (c *vault) lease(seconds int64, renewable bool) {
+	c.renewable = renewable
+	c.expiry = time.Time{}
+
+	if seconds > 0 {
+		c.expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+}
+
+// read retrieves the secret from the KV v2 secrets engine.
+func (c *vault) read(ctx context.Context) (string, error) {
+	path := fmt.Sprintf("%s/data/%s", strings.Trim(c.conf.Mount, "/"), strings.Trim(c.conf.Path, "/"))
+	if c.conf.Version > 0 {
+		path = fmt.Sprintf("%s?version=%d", path, c.conf.Version)
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var kv struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	if err := json.Unmarshal(resp.Data, &kv); err != nil {
+		return "", err
+	}
+
+	if c.conf.Field == "" {
+		b, err := json.Marshal(kv.Data)
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	}
+
+	v, ok := kv.Data[c.conf.Field]
+	if !ok {
+		return "", fmt.Errorf("%s: %s: %v", c.conf.Path, c.conf.Field, errVaultFieldNotFound)
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// do sends a request to the Vault API. Errors never contain the token.
+func (c *vault) do(ctx context.Context, method, path string, body interface{}) (*vaultResponse, error) {
+	u, err := url.JoinPath(c.conf.Address, "v1")
+	if err != nil {
+		return nil, err
+	}
+	u = fmt.Sprintf("%s/%s", u, path)
+
+	var headers []ihttp.Header
+	if c.token != "" {
+		headers = append(headers, ihttp.Header{Key: "X-Vault-Token", Value: c.token})
+	}
+
+	if c.conf.Namespace != "" {
+		headers = append(headers, ihttp.Header{Key: "X-Vault-Namespace", Value: c.conf.Namespace})
+	}
+
+	var resp *http.Response
+	if method == http.MethodGet {
+		resp, err = c.client.Get(ctx, u, headers...)
+	} else {
+		b, jsonErr := json.Marshal(body)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+
+		headers = append(headers, ihttp.Header{Key: "Content-Type", Value: "application/json"})
+		resp, err = c.client.Post(ctx, u, b, headers...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out vaultResponse
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%s: %s: %s", path, resp.Status, strings.Join(out.Errors, ", "))
+	}
+
+	return &out, nil
+}
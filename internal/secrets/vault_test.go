@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+// vaultTestServer is a stand-in for a Vault server that supports AppRole login,
+// token renewal, and reading a KV v2 secret. Tokens issued by login expire after
+// the lease, and tokens issued by renewal expire after one hour.
+func vaultTestServer(t *testing.T, lease int) (*httptest.Server, map[string]int) {
+	t.Helper()
+
+	calls := make(map[string]int)
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		calls["login"]++
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["role_id"] != "foo" || body["secret_id"] != "bar" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"auth":{"client_token":"s.token","lease_duration":%d,"renewable":true}}`, lease)
+	})
+
+	mux.HandleFunc("POST /v1/auth/token/renew-self", func(w http.ResponseWriter, r *http.Request) {
+		calls["renew"]++
+
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"s.token","lease_duration":3600,"renewable":true}}`))
+	})
+
+	mux.HandleFunc("GET /v1/secret/data/app/db", func(w http.ResponseWriter, r *http.Request) {
+		calls["read"]++
+
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"data":{"user":"foo","password":"baz"},"metadata":{"version":1}}}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, calls
+}
+
+var vaultTests = []struct {
+	name     string
+	field    string
+	lease    int
+	expected string
+	calls    map[string]int
+}{
+	{
+		"field",
+		"password",
+		3600,
+		"baz",
+		map[string]int{"login": 1, "read": 2},
+	},
+	{
+		"secret",
+		"",
+		3600,
+		`{"password":"baz","user":"foo"}`,
+		map[string]int{"login": 1, "read": 2},
+	},
+	// The lease expires before the next retrieval, so the token is renewed.
+	{
+		"renew",
+		"password",
+		60,
+		"baz",
+		map[string]int{"login": 1, "renew": 1, "read": 2},
+	},
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range vaultTests {
+		t.Run(test.name, func(t *testing.T) {
+			srv, calls := vaultTestServer(t, test.lease)
+
+			cfg := config.Config{
+				Type: "vault",
+				Settings: map[string]interface{}{
+					"id":      "vault",
+					"address": srv.URL,
+					"path":    "app/db",
+					"field":   test.field,
+					"auth": map[string]interface{}{
+						"type":      "approle",
+						"role_id":   "foo",
+						"secret_id": "bar",
+					},
+				},
+			}
+
+			ret, err := New(ctx, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if err := ret.Retrieve(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			interp, err := Interpolate(ctx, "${SECRET:vault}")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if interp != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, interp)
+			}
+
+			for k, v := range test.calls {
+				if calls[k] != v {
+					t.Errorf("expected %d %s calls, got %d", v, k, calls[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package sigma
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/brexhq/substation/v2/config"
+)
+
+// tokenize splits a condition into identifiers, keywords, and parentheses.
+func tokenize(s string) []string {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	return strings.Fields(s)
+}
+
+// parser compiles a condition (e.g., "selection and not 1 of filter_*") using
+// this grammar:
+//
+//	or      = and { "or" and }
+//	and     = not { "and" not }
+//	not     = "not" not | primary
+//	primary = "(" or ")" | ( "1" | "any" | "all" ) "of" pattern | identifier
+type parser struct {
+	tokens   []string
+	pos      int
+	searches map[string]config.Config
+}
+
+func (p *parser) parse() (config.Config, error) {
+	cfg, err := p.or()
+	if err != nil {
+		return cfg, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return cfg, fmt.Errorf("%s: %v", p.tokens[p.pos], errInvalidCondition)
+	}
+
+	return cfg, nil
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return strings.ToLower(p.tokens[p.pos])
+	}
+
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.tokens[p.pos]
+	p.pos++
+
+	return t
+}
+
+func (p *parser) or() (config.Config, error) {
+	cfg, err := p.and()
+	if err != nil {
+		return cfg, err
+	}
+
+	cfgs := []config.Config{cfg}
+	for p.peek() == "or" {
+		p.next()
+
+		cfg, err := p.and()
+		if err != nil {
+			return cfg, err
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return combine("meta_any", cfgs), nil
+}
+
+func (p *parser) and() (config.Config, error) {
+	cfg, err := p.not()
+	if err != nil {
+		return cfg, err
+	}
+
+	cfgs := []config.Config{cfg}
+	for p.peek() == "and" {
+		p.next()
+
+		cfg, err := p.not()
+		if err != nil {
+			return cfg, err
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return combine("meta_all", cfgs), nil
+}
+
+func (p *parser) not() (config.Config, error) {
+	if p.peek() != "not" {
+		return p.primary()
+	}
+
+	p.next()
+	cfg, err := p.not()
+	if err != nil {
+		return cfg, err
+	}
+
+	return config.Config{
+		Type:     "meta_none",
+		Settings: map[string]interface{}{"conditions": []config.Config{cfg}},
+	}, nil
+}
+
+func (p *parser) primary() (config.Config, error) {
+	switch t := p.peek(); t {
+	case "":
+		return config.Config{}, fmt.Errorf("unexpected end: %v", errInvalidCondition)
+	case "(":
+		p.next()
+		cfg, err := p.or()
+		if err != nil {
+			return cfg, err
+		}
+
+		if p.peek() != ")" {
+			return cfg, fmt.Errorf("missing ): %v", errInvalidCondition)
+		}
+
+		p.next()
+		return cfg, nil
+	case ")", "and", "or", "of":
+		return config.Config{}, fmt.Errorf("%s: %v", t, errInvalidCondition)
+	case "1", "any", "all":
+		if p.pos+1 >= len(p.tokens) || strings.ToLower(p.tokens[p.pos+1]) != "of" {
+			break
+		}
+
+		p.next()
+		p.next()
+		if p.pos == len(p.tokens) {
+			return config.Config{}, fmt.Errorf("%s of: %v", t, errInvalidCondition)
+		}
+
+		cfgs, err := p.match(p.next())
+		if err != nil {
+			return config.Config{}, err
+		}
+
+		if t == "all" {
+			return combine("meta_all", cfgs), nil
+		}
+
+		return combine("meta_any", cfgs), nil
+	}
+
+	id := p.next()
+	cfg, ok := p.searches[id]
+	if !ok {
+		return cfg, fmt.Errorf("%s: %v", id, errUnknownIdentifier)
+	}
+
+	return cfg, nil
+}
+
+// match returns the search identifiers that match a pattern. "them" matches
+// all identifiers that do not start with an underscore.
+func (p *parser) match(pattern string) ([]config.Config, error) {
+	var names []string
+	for name := range p.searches {
+		if pattern == "them" {
+			if !strings.HasPrefix(name, "_") {
+				names = append(names, name)
+			}
+
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %v", pattern, errUnknownIdentifier)
+	}
+
+	sort.Strings(names)
+
+	cfgs := make([]config.Config, 0, len(names))
+	for _, name := range names {
+		cfgs = append(cfgs, p.searches[name])
+	}
+
+	return cfgs, nil
+}
@@ -0,0 +1,511 @@
+// Package sigma compiles Sigma rules (https://sigmahq.io) into conditions.
+//
+// Each rule's detection is compiled into a tree of existing conditions (meta_all,
+// meta_any, meta_none, string_*, and network_ip_in_cidr), so rules are evaluated
+// the same way as any other condition.
+package sigma
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/brexhq/substation/v2/config"
+
+	"github.com/brexhq/substation/v2/internal/file"
+)
+
+var (
+	// errMissingDetection is returned when a rule does not have a detection.
+	errMissingDetection = fmt.Errorf("missing detection")
+	// errMissingCondition is returned when a rule's detection does not have a condition.
+	errMissingCondition = fmt.Errorf("missing condition")
+	// errUnsupportedModifier is returned when a field uses an unsupported modifier.
+	errUnsupportedModifier = fmt.Errorf("unsupported modifier")
+	// errUnknownIdentifier is returned when a condition refers to a search
+	// identifier that is not in the detection.
+	errUnknownIdentifier = fmt.Errorf("unknown identifier")
+	// errInvalidCondition is returned when a condition cannot be parsed.
+	errInvalidCondition = fmt.Errorf("invalid condition")
+	// errInvalidValue is returned when a field's value cannot be compiled.
+	errInvalidValue = fmt.Errorf("invalid value")
+)
+
+// Rule is a compiled Sigma rule.
+type Rule struct {
+	// ID is the rule's ID. If the rule does not have an ID, then this is the rule's title.
+	ID    string
+	Title string
+	// Condition is a condition config that returns true if a message matches the rule.
+	Condition config.Config
+}
+
+type rule struct {
+	ID        string                 `json:"id"`
+	Title     string                 `json:"title"`
+	Detection map[string]interface{} `json:"detection"`
+}
+
+// Load compiles inline rules and rules that are stored in files. Files can be
+// either a path on local disk, an HTTP(S) URL, or an AWS S3 URL. Fields maps
+// Sigma field names to message keys; fields that are not in the map are used
+// as message keys without changes.
+func Load(ctx context.Context, rules []string, files []string, fields map[string]string) ([]Rule, error) {
+	var out []Rule
+	for _, r := range rules {
+		c, err := Compile([]byte(r), fields)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, c...)
+	}
+
+	for _, f := range files {
+		path, err := file.Get(ctx, f)
+		defer os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := Compile(b, fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+
+		out = append(out, c...)
+	}
+
+	return out, nil
+}
+
+// Compile compiles one or more rules from YAML. Multiple rules are separated
+// by "---".
+func Compile(b []byte, fields map[string]string) ([]Rule, error) {
+	var rules []Rule
+	for _, doc := range splitDocuments(b) {
+		j, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		var r rule
+		if err := json.Unmarshal(j, &r); err != nil {
+			return nil, err
+		}
+
+		c := compiler{fields: fields}
+		cfg, err := c.compile(r)
+		if err != nil {
+			if n := r.name(); n != "" {
+				return nil, fmt.Errorf("rule %s: %v", n, err)
+			}
+
+			return nil, err
+		}
+
+		rules = append(rules, Rule{
+			ID:        r.name(),
+			Title:     r.Title,
+			Condition: cfg,
+		})
+	}
+
+	return rules, nil
+}
+
+func (r rule) name() string {
+	if r.ID != "" {
+		return r.ID
+	}
+
+	return r.Title
+}
+
+// splitDocuments splits a YAML stream into documents and removes empty documents.
+func splitDocuments(b []byte) [][]byte {
+	var docs [][]byte
+	var buf bytes.Buffer
+
+	flush := func() {
+		if len(bytes.TrimSpace(buf.Bytes())) > 0 {
+			docs = append(docs, append([]byte(nil), buf.Bytes()...))
+		}
+
+		buf.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(b)+1)
+	for scanner.Scan() {
+		if strings.TrimRight(scanner.Text(), " \t") == "---" {
+			flush()
+			continue
+		}
+
+		buf.Write(scanner.Bytes())
+		buf.WriteByte('\n')
+	}
+
+	flush()
+	return docs
+}
+
+type compiler struct {
+	fields map[string]string
+	// searches contains compiled search identifiers.
+	searches map[string]config.Config
+}
+
+func (c *compiler) compile(r rule) (config.Config, error) {
+	if len(r.Detection) == 0 {
+		return config.Config{}, errMissingDetection
+	}
+
+	cond, ok := r.Detection["condition"]
+	if !ok {
+		return config.Config{}, errMissingCondition
+	}
+
+	c.searches = make(map[string]config.Config)
+	for k, v := range r.Detection {
+		if k == "condition" || k == "timeframe" {
+			continue
+		}
+
+		cfg, err := c.search(v)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("%s: %v", k, err)
+		}
+
+		c.searches[k] = cfg
+	}
+
+	// Multiple conditions are combined with "or".
+	var exprs []string
+	switch v := cond.(type) {
+	case string:
+		exprs = append(exprs, v)
+	case []interface{}:
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return config.Config{}, fmt.Errorf("condition: %v", errInvalidCondition)
+			}
+
+			exprs = append(exprs, s)
+		}
+	default:
+		return config.Config{}, fmt.Errorf("condition: %v", errInvalidCondition)
+	}
+
+	var cfgs []config.Config
+	for _, e := range exprs {
+		p := parser{tokens: tokenize(e), searches: c.searches}
+		cfg, err := p.parse()
+		if err != nil {
+			return config.Config{}, fmt.Errorf("condition %q: %v", e, err)
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return combine("meta_any", cfgs), nil
+}
+
+// search compiles a search identifier. Maps are combined with "and", lists of
+// maps are combined with "or", and lists of values are keywords that are
+// searched for in the entire message.
+func (c *compiler) search(v interface{}) (config.Config, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return c.fieldMap(v)
+	case []interface{}:
+		if len(v) == 0 {
+			return config.Config{}, errInvalidValue
+		}
+
+		if _, ok := v[0].(map[string]interface{}); !ok {
+			return c.field("", v)
+		}
+
+		var cfgs []config.Config
+		for _, e := range v {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return config.Config{}, errInvalidValue
+			}
+
+			cfg, err := c.fieldMap(m)
+			if err != nil {
+				return config.Config{}, err
+			}
+
+			cfgs = append(cfgs, cfg)
+		}
+
+		return combine("meta_any", cfgs), nil
+	default:
+		return c.field("", v)
+	}
+}
+
+func (c *compiler) fieldMap(m map[string]interface{}) (config.Config, error) {
+	if len(m) == 0 {
+		return config.Config{}, errInvalidValue
+	}
+
+	// Keys are sorted so that rules always compile to the same config.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var cfgs []config.Config
+	for _, k := range keys {
+		cfg, err := c.field(k, m[k])
+		if err != nil {
+			return config.Config{}, err
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return combine("meta_all", cfgs), nil
+}
+
+// field compiles a field and its modifiers (e.g., CommandLine|contains|all). If
+// the field is empty, then values are searched for in the entire message.
+func (c *compiler) field(key string, v interface{}) (config.Config, error) {
+	parts := strings.Split(key, "|")
+	name, mods := parts[0], parts[1:]
+
+	src := name
+	if f, ok := c.fields[name]; ok {
+		src = f
+	}
+
+	// Null values match fields that are missing or empty.
+	if v == nil {
+		if src == "" {
+			return config.Config{}, errInvalidValue
+		}
+
+		return config.Config{
+			Type: "string_match",
+			Settings: map[string]interface{}{
+				"object":  map[string]interface{}{"source_key": src},
+				"pattern": "^$",
+			},
+		}, nil
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		values = []interface{}{v}
+	}
+
+	if len(values) == 0 {
+		return config.Config{}, fmt.Errorf("%s: %v", key, errInvalidValue)
+	}
+
+	var all bool
+	for _, m := range mods {
+		if m == "all" {
+			all = true
+		}
+	}
+
+	var cfgs []config.Config
+	for _, val := range values {
+		cfg, err := value(val, mods)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("%s: %v", key, err)
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	if src == "" {
+		if all {
+			return combine("meta_all", cfgs), nil
+		}
+
+		return combine("meta_any", cfgs), nil
+	}
+
+	// If the field is an array, then any element in the array can match.
+	if !all {
+		return config.Config{
+			Type: "meta_any",
+			Settings: map[string]interface{}{
+				"object":     map[string]interface{}{"source_key": src},
+				"conditions": cfgs,
+			},
+		}, nil
+	}
+
+	var each []config.Config
+	for _, cfg := range cfgs {
+		each = append(each, config.Config{
+			Type: "meta_any",
+			Settings: map[string]interface{}{
+				"object":     map[string]interface{}{"source_key": src},
+				"conditions": []config.Config{cfg},
+			},
+		})
+	}
+
+	return combine("meta_all", each), nil
+}
+
+// value compiles a value into a condition that inspects the message data.
+func value(v interface{}, mods []string) (config.Config, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		return config.Config{}, errInvalidValue
+	}
+
+	var (
+		match    string
+		isRegex  bool
+		isCIDR   bool
+		isBase64 bool
+		reFlags  string
+	)
+
+	for _, m := range mods {
+		switch m {
+		case "contains", "startswith", "endswith":
+			match = m
+		case "re":
+			isRegex = true
+		case "i", "m", "s":
+			reFlags += m
+		case "cidr":
+			isCIDR = true
+		case "base64":
+			isBase64 = true
+		case "all":
+		default:
+			return config.Config{}, fmt.Errorf("%s: %v", m, errUnsupportedModifier)
+		}
+	}
+
+	switch {
+	case isRegex:
+		if _, err := regexp.Compile(s); err != nil {
+			return config.Config{}, err
+		}
+
+		if reFlags != "" {
+			s = "(?" + reFlags + ")" + s
+		}
+
+		return config.Config{
+			Type:     "string_match",
+			Settings: map[string]interface{}{"pattern": s},
+		}, nil
+	case isCIDR:
+		return config.Config{
+			Type:     "network_ip_in_cidr",
+			Settings: map[string]interface{}{"cidrs": []string{s}},
+		}, nil
+	case isBase64:
+		// Encoded values are case-sensitive and do not contain wildcards.
+		s = base64.StdEncoding.EncodeToString([]byte(s))
+
+		t := "string_equal_to"
+		switch match {
+		case "contains":
+			t = "string_contains"
+		case "startswith":
+			t = "string_starts_with"
+		case "endswith":
+			t = "string_ends_with"
+		}
+
+		return config.Config{
+			Type:     t,
+			Settings: map[string]interface{}{"value": s},
+		}, nil
+	}
+
+	// Values are case-insensitive and wildcards can match newlines.
+	p := wildcard(s)
+	switch match {
+	case "contains":
+	case "startswith":
+		p = "^" + p
+	case "endswith":
+		p = p + "$"
+	default:
+		p = "^" + p + "$"
+	}
+
+	return config.Config{
+		Type:     "string_match",
+		Settings: map[string]interface{}{"pattern": "(?is)" + p},
+	}, nil
+}
+
+// wildcard converts a Sigma value into a regular expression. "*" matches any
+// number of characters, "?" matches one character, and "\" escapes wildcards.
+func wildcard(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '*' || s[i+1] == '?' || s[i+1] == '\\') {
+				i++
+			}
+
+			b.WriteString(regexp.QuoteMeta(string(s[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(s[i])))
+		}
+	}
+
+	return b.String()
+}
+
+// combine returns a single config if there is only one, otherwise the configs
+// are combined using a meta condition.
+func combine(t string, cfgs []config.Config) config.Config {
+	if len(cfgs) == 1 {
+		return cfgs[0]
+	}
+
+	return config.Config{
+		Type:     t,
+		Settings: map[string]interface{}{"conditions": cfgs},
+	}
+}
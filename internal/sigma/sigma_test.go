@@ -0,0 +1,98 @@
+package sigma
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	rules, err := Compile([]byte(`
+title: A
+id: a
+detection:
+  selection:
+    CommandLine|contains: foo
+  condition: selection
+---
+title: B
+detection:
+  selection:
+    CommandLine|contains: bar
+  condition: selection
+`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+
+	if rules[0].ID != "a" || rules[1].ID != "B" {
+		t.Errorf("expected IDs a and B, got %s and %s", rules[0].ID, rules[1].ID)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		err  error
+	}{
+		{
+			"missing detection",
+			"title: A\n",
+			errMissingDetection,
+		},
+		{
+			"missing condition",
+			"detection:\n  selection:\n    a: b\n",
+			errMissingCondition,
+		},
+		{
+			"unsupported modifier",
+			"detection:\n  selection:\n    a|windash: b\n  condition: selection\n",
+			errUnsupportedModifier,
+		},
+		{
+			"unknown identifier",
+			"detection:\n  selection:\n    a: b\n  condition: selection and filter\n",
+			errUnknownIdentifier,
+		},
+		{
+			"invalid condition",
+			"detection:\n  selection:\n    a: b\n  condition: (selection\n",
+			errInvalidCondition,
+		},
+		{
+			"aggregation",
+			"detection:\n  selection:\n    a: b\n  condition: selection | count() > 5\n",
+			errInvalidCondition,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compile([]byte(test.rule), nil)
+			if err == nil || !strings.Contains(err.Error(), test.err.Error()) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestWildcard(t *testing.T) {
+	tests := map[string]string{
+		`foo*bar`:   `foo.*bar`,
+		`foo?`:      `foo.`,
+		`C:\a.exe`:  `C:\\a\.exe`,
+		`\*literal`: `\*literal`,
+		`a\\*`:      `a\\.*`,
+	}
+
+	for in, expected := range tests {
+		if out := wildcard(in); out != expected {
+			t.Errorf("%s: expected %s, got %s", in, expected, out)
+		}
+	}
+}
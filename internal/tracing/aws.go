@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AppendAWSMiddleware adds a middleware to AWS SDK clients that creates a span
+// for every API call. The span context is also available to every other
+// middleware in the stack.
+func AppendAWSMiddleware(apiOptions *[]func(*middleware.Stack) error) {
+	*apiOptions = append(*apiOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("SubstationTracing", awsSpan), middleware.Before)
+	})
+}
+
+func awsSpan(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	service := awsmiddleware.GetServiceID(ctx)
+	operation := awsmiddleware.GetOperationName(ctx)
+
+	ctx, span := Tracer().Start(ctx, fmt.Sprintf("%s.%s", service, operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "aws-api"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", operation),
+			attribute.String("cloud.region", awsmiddleware.GetRegion(ctx)),
+		),
+	)
+	defer span.End()
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+		span.SetAttributes(attribute.String("aws.request_id", id))
+	}
+
+	return out, metadata, err
+}
@@ -0,0 +1,164 @@
+// Package tracing provides OpenTelemetry tracing for applications.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName identifies the tracer that creates spans.
+	instrumentationName = "github.com/brexhq/substation/v2"
+)
+
+var (
+	mu       sync.RWMutex
+	provider *sdktrace.TracerProvider
+	// errInvalidProtocol is returned when the exporter protocol is not supported.
+	errInvalidProtocol = fmt.Errorf("invalid protocol")
+	// errInvalidSampleRate is returned when the sample rate is not between 0 and 1.
+	errInvalidSampleRate = fmt.Errorf("sample rate must be between 0 and 1")
+)
+
+// Config configures an OTLP trace exporter.
+type Config struct {
+	// Protocol is the OTLP transport protocol. Must be one of:
+	//
+	// - http: OTLP/HTTP with protobuf encoding
+	//
+	// - grpc: OTLP/gRPC
+	//
+	// This is optional and defaults to http.
+	Protocol string
+	// Endpoint is the host and port of the OTLP receiver (e.g., localhost:4318).
+	//
+	// This is optional and defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment
+	// variable or the default endpoint for the protocol.
+	Endpoint string
+	// Insecure disables TLS.
+	Insecure bool
+	// Headers are sent with every export request (e.g., API keys).
+	Headers map[string]string
+	// SampleRate is the fraction of traces that are sampled. If a span is
+	// created from a sampled parent span, then it is always sampled.
+	//
+	// This is optional and defaults to 1 (every trace is sampled).
+	SampleRate float64
+}
+
+// Setup configures the global tracer provider and propagator. If tracing is
+// already enabled, then this has no effect.
+func Setup(ctx context.Context, cfg Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider != nil {
+		return nil
+	}
+
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 1
+	}
+
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return fmt.Errorf("tracing: %v", errInvalidSampleRate)
+	}
+
+	var exp sdktrace.SpanExporter
+	switch cfg.Protocol {
+	case "", "http":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+
+		e, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return fmt.Errorf("tracing: %v", err)
+		}
+
+		exp = e
+	case "grpc":
+		var opts []otlptracegrpc.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+
+		e, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return fmt.Errorf("tracing: %v", err)
+		}
+
+		exp = e
+	default:
+		return fmt.Errorf("tracing: protocol %s: %v", cfg.Protocol, errInvalidProtocol)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.Default()),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return nil
+}
+
+// IsEnabled returns true if tracing is configured. This can be used to avoid
+// instrumentation when tracing is disabled.
+func IsEnabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return provider != nil
+}
+
+// Tracer returns the tracer that is used for all spans. If tracing is disabled,
+// then spans are not recorded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Flush exports all spans that have ended. This should be called before an
+// application exits or is suspended (e.g., at the end of an AWS Lambda invocation).
+func Flush(ctx context.Context) error {
+	mu.RLock()
+	p := provider
+	mu.RUnlock()
+
+	if p == nil {
+		return nil
+	}
+
+	return p.ForceFlush(ctx)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/brexhq/substation/v2/config"
 	"github.com/brexhq/substation/v2/message"
 	"github.com/brexhq/substation/v2/transform"
+
+	"github.com/brexhq/substation/v2/internal/metrics"
+	"github.com/brexhq/substation/v2/internal/secrets"
+	"github.com/brexhq/substation/v2/internal/tracing"
 )
 
 //go:embed substation.libsonnet
@@ -21,6 +25,12 @@ var errNoTransforms = fmt.Errorf("no transforms configured")
 type Config struct {
 	// Transforms contains a list of data transformatons that are executed.
 	Transforms []config.Config `json:"transforms"`
+	// Telemetry enables metrics for every transform in Transforms. This is
+	// optional and defaults to no metrics.
+	Telemetry *Telemetry `json:"telemetry,omitempty"`
+	// Tracing enables OpenTelemetry tracing for every transform in Transforms.
+	// This is optional and defaults to no tracing.
+	Tracing *Tracing `json:"tracing,omitempty"`
 }
 
 // Substation provides access to data transformation functions.
@@ -46,11 +56,37 @@ func New(ctx context.Context, cfg Config, opts ...func(*Substation)) (*Substatio
 		o(sub)
 	}
 
+	if cfg.Tracing != nil {
+		if err := tracing.Setup(ctx, tracing.Config(*cfg.Tracing)); err != nil {
+			return nil, fmt.Errorf("substation: %v", err)
+		}
+	}
+
+	var gen metrics.Generator
+	if cfg.Telemetry != nil {
+		g, err := metrics.New(ctx, cfg.Telemetry.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("substation: telemetry: %v", err)
+		}
+
+		gen = g
+	}
+
 	// Create transforms from the configuration.
 	for _, c := range cfg.Transforms {
 		t, err := sub.factory(ctx, c)
 		if err != nil {
-			return nil, err
+			return nil, secrets.RedactError(err)
+		}
+
+		// Transforms are only wrapped if telemetry is enabled, so there
+		// is no overhead when it is disabled.
+		if gen != nil {
+			t = newTelemetryTransformer(c, t, gen, cfg.Telemetry.Attributes)
+		}
+
+		if cfg.Tracing != nil {
+			t = newTracingTransformer(c, t)
 		}
 
 		sub.tforms = append(sub.tforms, t)
@@ -69,17 +105,27 @@ func WithTransformFactory(fac transform.Factory) func(*Substation) {
 // Transform runs the configured data transformation functions on the
 // provided messages.
 //
+// If tracing is enabled, then each call starts a trace (or continues the
+// trace in the context), so messages that are transformed individually
+// are sampled individually. Spans are exported when a ctrl message is
+// transformed.
+//
 // This is safe to use concurrently.
 func (s *Substation) Transform(ctx context.Context, msg ...*message.Message) ([]*message.Message, error) {
-	return transform.Apply(ctx, s.tforms, msg...)
+	if s.cfg.Tracing == nil {
+		return transform.Apply(ctx, s.tforms, msg...)
+	}
+
+	return s.trace(ctx, msg...)
 }
 
-// String returns a JSON representation of the configuration.
+// String returns a JSON representation of the configuration. Secrets are
+// removed from the output.
 func (s *Substation) String() string {
 	b, err := json.Marshal(s.cfg)
 	if err != nil {
 		return fmt.Sprintf("substation: %v", err)
 	}
 
-	return string(b)
+	return secrets.Redact(string(b))
 }
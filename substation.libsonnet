@@ -2,7 +2,7 @@ local helpers = {
   // If the input is not an array, then this returns it as an array.
   make_array(i): if !std.isArray(i) then [i] else i,
   abbv(settings): std.mergePatch(settings, {
-    object: if std.objectHas(settings, 'object') then $.abbv_obj(settings.object) else if std.objectHas(settings, 'obj') then $.abbv_obj(settings.obj) else null,
+    object: if std.objectHas(settings, 'object') then $.abbv_obj(settings.object) else if std.objectHas(settings, 'obj') then std.mergePatch(settings.obj, $.abbv_obj(settings.obj)) else null,
     obj: null,
   }),
   abbv_obj(s): {
@@ -50,12 +50,37 @@ local helpers = {
         type: 'meta_none',
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
+      threshold(settings={}): {
+        local default = {
+          object: $.config.object,
+          threshold: null,
+          window: null,
+          window_type: 'fixed',
+          once_per_window: false,
+          prefix: null,
+          kv_store: null,
+        },
+
+        type: 'meta_threshold',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
     },
     fmt: $.condition.format,
     format: {
       json(settings={}): {
         type: 'format_json',
       },
+      json_schema(settings={}): {
+        local default = {
+          object: $.config.object,
+          schema: null,
+          file: null,
+        },
+
+        type: 'format_json_schema',
+        // The schema is not pruned or merged because empty and null values are meaningful.
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))) + (if std.objectHas(settings, 'schema') then { schema: settings.schema } else {}),
+      },
       mime(settings={}): {
         local default = {
           object: $.config.object,
@@ -172,6 +197,15 @@ local helpers = {
           type: 'network_ip_global_unicast',
           settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
         },
+        in_cidr(settings={}): {
+          local default = $.condition.network.ip.default {
+            cidrs: null,
+            file: null,
+          },
+
+          type: 'network_ip_in_cidr',
+          settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+        },
         link_local_multicast(settings={}): {
           local default = $.condition.network.ip.default,
 
@@ -222,6 +256,28 @@ local helpers = {
         },
       },
     },
+    obj: $.condition.object,
+    object: {
+      jq(settings={}): {
+        local default = {
+          object: $.config.object,
+          filter: null,
+        },
+
+        type: 'object_jq',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+    },
+    sigma(settings={}): {
+      local default = {
+        rules: null,
+        files: null,
+        fields: null,
+      },
+
+      type: 'sigma',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
     str: $.condition.string,
     string: {
       default: {
@@ -235,6 +291,24 @@ local helpers = {
         type: 'string_contains',
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
+      contains_any(settings={}): {
+        local default = {
+          object: $.config.object,
+          values: null,
+          file: null,
+          case_insensitive: false,
+          metadata_key: null,
+        },
+
+        type: 'string_contains_any',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      entropy_greater_than(settings={}): {
+        local default = $.condition.string.default,
+
+        type: 'string_entropy_greater_than',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
       eq(settings={}): $.condition.string.equal_to(settings=settings),
       equal_to(settings={}): {
         local default = $.condition.string.default,
@@ -249,6 +323,44 @@ local helpers = {
         type: 'string_greater_than',
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
+      in_bloom_filter(settings={}): {
+        local default = {
+          object: $.config.object,
+          kv_store: null,
+        },
+
+        type: 'string_in_bloom_filter',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      in_set(settings={}): {
+        local default = {
+          object: $.config.object,
+          values: null,
+          kv_store: null,
+          case_insensitive: false,
+        },
+
+        type: 'string_in_set',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      is_base64(settings={}): {
+        local default = {
+          object: $.config.object,
+          min_length: null,
+        },
+
+        type: 'string_is_base64',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      is_hex(settings={}): {
+        local default = {
+          object: $.config.object,
+          min_length: null,
+        },
+
+        type: 'string_is_hex',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
       lt(settings={}): $.condition.string.less_than(settings=settings),
       less_than(settings={}): {
         local default = $.condition.string.default,
@@ -279,9 +391,65 @@ local helpers = {
         type: 'string_match',
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
+      similar_to(settings={}): {
+        local default = {
+          object: $.config.object,
+          values: null,
+          algorithm: null,
+          threshold: null,
+        },
+
+        type: 'string_similar_to',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+    },
+    time: {
+      default: {
+        object: $.config.object { compare_key: null },
+        value: null,
+        duration: null,
+        format: null,
+        location: null,
+      },
+      after(settings={}): {
+        local default = $.condition.time.default,
+
+        type: 'time_after',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      before(settings={}): {
+        local default = $.condition.time.default,
+
+        type: 'time_before',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+      within(settings={}): {
+        local default = {
+          object: $.config.object,
+          start: null,
+          end: null,
+          days: null,
+          time_zone: null,
+          format: null,
+          location: null,
+        },
+
+        type: 'time_within',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
     },
     util: $.condition.utility,
     utility: {
+      hash_sample(settings={}): {
+        local default = {
+          object: $.config.object,
+          percentage: null,
+          seed: null,
+        },
+
+        type: 'utility_hash_sample',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
       random(settings={}): {
         type: 'utility_random',
       },
@@ -401,6 +569,7 @@ local helpers = {
         default: {
           object: $.config.object,
           request: $.config.request,
+          cache: null,
         },
         domain_lookup(settings={}): {
           local type = 'enrich_dns_domain_lookup',
@@ -433,7 +602,7 @@ local helpers = {
         },
         get(settings={}): {
           local type = 'enrich_http_get',
-          local default = $.transform.enrich.http.default { id: helpers.id(type, settings) },
+          local default = $.transform.enrich.http.default { cache: null, id: helpers.id(type, settings) },
 
           type: type,
           settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
@@ -458,14 +627,14 @@ local helpers = {
         item: {
           get(settings={}): {
             local type = 'enrich_kv_store_get',
-            local default = $.transform.enrich.kv_store.default { id: helpers.id(type, settings) },
+            local default = $.transform.enrich.kv_store.default { batch: null, id: helpers.id(type, settings) },
 
             type: type,
             settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
           },
           set(settings={}): {
             local type = 'enrich_kv_store_set',
-            local default = $.transform.enrich.kv_store.default { ttl_key: null, ttl_offset: '0s', id: helpers.id(type, settings) },
+            local default = $.transform.enrich.kv_store.default { ttl_key: null, ttl_offset: '0s', set_condition: null, batch: null, id: helpers.id(type, settings) },
 
             type: type,
             settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
@@ -482,6 +651,19 @@ local helpers = {
           },
         },
       },
+      sigma(settings={}): {
+        local type = 'enrich_sigma',
+        local default = {
+          id: helpers.id(type, settings),
+          object: $.config.object,
+          rules: null,
+          files: null,
+          fields: null,
+        },
+
+        type: type,
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
     },
     fmt: $.transform.format,
     format: {
@@ -545,6 +727,14 @@ local helpers = {
           settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
         },
       },
+      json_schema(settings={}): {
+        local type = 'format_json_schema',
+        local default = $.transform.format.default { id: helpers.id(type, settings), schema: null, file: null },
+
+        type: type,
+        // The schema is not pruned or merged because empty and null values are meaningful.
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))) + (if std.objectHas(settings, 'schema') then { schema: settings.schema } else {}),
+      },
     },
     hash: {
       default: {
@@ -956,6 +1146,44 @@ local helpers = {
           settings: std.prune(std.mergePatch(default, helpers.abbv(s))),
         },
       },
+      azure: {
+        blob_storage(settings={}): {
+          local type = 'send_azure_blob_storage',
+          local default = {
+            id: helpers.id(type, settings),
+            batch: $.config.batch,
+            azure: $.config.azure,
+            auxiliary_transforms: null,
+            file_path: $.file_path,
+          },
+
+          local s = std.mergePatch(settings, {
+            auxiliary_transforms: if std.objectHas(settings, 'auxiliary_transforms') then settings.auxiliary_transforms else if std.objectHas(settings, 'aux_tforms') then settings.aux_tforms else null,
+            aux_tforms: null,
+          }),
+
+          type: type,
+          settings: std.prune(std.mergePatch(default, helpers.abbv(s))),
+        },
+        event_hubs(settings={}): {
+          local type = 'send_azure_event_hubs',
+          local default = {
+            id: helpers.id(type, settings),
+            batch: $.config.batch,
+            azure: $.config.azure,
+            auxiliary_transforms: null,
+            use_batch_key_as_partition_key: false,
+          },
+
+          local s = std.mergePatch(settings, {
+            auxiliary_transforms: if std.objectHas(settings, 'auxiliary_transforms') then settings.auxiliary_transforms else if std.objectHas(settings, 'aux_tforms') then settings.aux_tforms else null,
+            aux_tforms: null,
+          }),
+
+          type: type,
+          settings: std.prune(std.mergePatch(default, helpers.abbv(s))),
+        },
+      },
       gcp: {
         storage(settings={}): {
           local type = 'send_gcp_storage',
@@ -1324,14 +1552,32 @@ local helpers = {
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
     },
+    bbolt(settings={}): {
+      local default = { file: null, compaction_interval: '1h' },
+
+      type: 'bbolt',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
+    bloom_filter(settings={}): {
+      local default = { file: null, format: 'text', column: null, delimiter: ',', header: null, false_positive_rate: 0.001, refresh_interval: null },
+
+      type: 'bloom_filter',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
+    cidr_file(settings={}): {
+      local default = { file: null, format: 'csv', column: null, delimiter: ',', header: null, refresh_interval: null },
+
+      type: 'cidr_file',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
     csv_file(settings={}): {
-      local default = { file: null, column: null, delimiter: ',', header: null },
+      local default = { file: null, column: null, delimiter: ',', header: null, refresh_interval: null },
 
       type: 'csv_file',
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
     },
     json_file(settings=$.defaults.kv_store.json_file.settings): {
-      local default = { file: null, is_lines: false },
+      local default = { file: null, is_lines: false, refresh_interval: null },
 
       type: 'json_file',
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
@@ -1343,13 +1589,19 @@ local helpers = {
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
     },
     mmdb(settings={}): {
-      local default = { file: null },
+      local default = { file: null, refresh_interval: null },
 
       type: 'mmdb',
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
     },
+    redis(settings={}): {
+      local default = { address: null, db: 0, username: null, password: null, enable_tls: false },
+
+      type: 'redis',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
     text_file(settings={}): {
-      local default = { file: null },
+      local default = { file: null, refresh_interval: null },
 
       type: 'text_file',
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
@@ -1369,6 +1621,16 @@ local helpers = {
         type: 'aws_secrets_manager',
         settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
       },
+      ssm_parameter_store(settings={}): {
+        local default = {
+          aws: $.config.aws,
+          id: null,
+          ttl_offset: null,
+        },
+
+        type: 'aws_ssm_parameter_store',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
     },
     environment_variable(settings={}): {
       local default = { id: null, name: null, ttl_offset: null },
@@ -1376,12 +1638,48 @@ local helpers = {
       type: 'environment_variable',
       settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
     },
+    file(settings={}): {
+      local default = { id: null, path: null, ttl_offset: null },
+
+      type: 'file',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
+    gcp: {
+      secret_manager(settings={}): {
+        local default = {
+          gcp: $.config.gcp,
+          id: null,
+          ttl_offset: null,
+        },
+
+        type: 'gcp_secret_manager',
+        settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+      },
+    },
+    vault(settings={}): {
+      local default = {
+        id: null,
+        address: null,
+        namespace: null,
+        mount: 'secret',
+        path: null,
+        field: null,
+        version: null,
+        auth: { type: 'token', mount: null, token: null, role_id: null, secret_id: null, role: null, token_path: null },
+        ttl_offset: null,
+      },
+
+      type: 'vault',
+      settings: std.prune(std.mergePatch(default, helpers.abbv(settings))),
+    },
   },
   // Mirrors structs from the internal/config package.
   config: {
     aws: { arn: null, assume_role_arn: null },
     gcp: { resource: null },
+    azure: { resource: null, connection_string: null },
     batch: { count: 1000, size: 1000 * 1000, duration: '1m' },
+    cache: { capacity: 1024, ttl: '1m', negative_ttl: null, metric: $.config.metric },
     metric: { name: null, attributes: null, destination: null },
     object: { source_key: null, target_key: null, batch_key: null },
     request: { timeout: '1s' },
@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/brexhq/substation/v2"
 	"github.com/brexhq/substation/v2/config"
@@ -235,3 +240,202 @@ func FuzzTestSubstation(f *testing.F) {
 		}
 	})
 }
+
+func TestSubstationTelemetry(t *testing.T) {
+	ctx := context.Background()
+
+	// Metrics are sent to a StatsD listener so that they can be inspected.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	cfg := substation.Config{
+		Transforms: []config.Config{
+			{
+				Type: "object_copy",
+				Settings: map[string]interface{}{
+					"id": "copy",
+					"object": map[string]interface{}{
+						"source_key": "a",
+						"target_key": "c",
+					},
+				},
+			},
+		},
+		Telemetry: &substation.Telemetry{
+			Destination: config.Config{
+				Type: "statsd",
+				Settings: map[string]interface{}{
+					"address": conn.LocalAddr().String(),
+				},
+			},
+		},
+	}
+
+	sub, err := substation.New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []*message.Message{
+		message.New().SetData([]byte(`{"a":"b"}`)),
+		message.New().AsControl(),
+	}
+
+	if _, err := sub.Transform(ctx, msg...); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"substation.transform_messages_in:1|c|#transform_id:copy,transform_type:object_copy",
+		"substation.transform_messages_out:1|c|#transform_id:copy,transform_type:object_copy",
+		"substation.transform_bytes_in:9|c|#transform_id:copy,transform_type:object_copy",
+		"substation.transform_bytes_out:17|c|#transform_id:copy,transform_type:object_copy",
+		"substation.transform_errors:0|c|#transform_id:copy,transform_type:object_copy",
+		"substation.transform_duration:",
+	}
+
+	buf := make([]byte, 1500)
+	for _, e := range expected {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(string(buf[:n]), e) {
+			t.Errorf("expected %s, got %s", e, buf[:n])
+		}
+	}
+}
+
+func TestSubstationTelemetryFlush(t *testing.T) {
+	ctx := context.Background()
+
+	// Metrics are exported to an OTLP receiver.
+	exports := make(chan struct{}, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/metrics" {
+			exports <- struct{}{}
+		}
+	}))
+	defer receiver.Close()
+
+	cfg := substation.Config{
+		Transforms: []config.Config{
+			{
+				Type: "object_copy",
+				Settings: map[string]interface{}{
+					"object": map[string]interface{}{
+						"source_key": "a",
+						"target_key": "c",
+					},
+				},
+			},
+		},
+		Telemetry: &substation.Telemetry{
+			Destination: config.Config{
+				Type: "otlp_metrics",
+				Settings: map[string]interface{}{
+					"endpoint": strings.TrimPrefix(receiver.URL, "http://"),
+					"insecure": true,
+					// Metrics are only exported by the ctrl message.
+					"export_interval": "1h",
+				},
+			},
+		},
+	}
+
+	sub, err := substation.New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sub.Transform(ctx, message.New().SetData([]byte(`{"a":"b"}`))); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-exports:
+		t.Fatal("unexpected export before ctrl message")
+	default:
+	}
+
+	if _, err := sub.Transform(ctx, message.New().AsControl()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-exports:
+	case <-time.After(time.Second):
+		t.Fatal("expected export after ctrl message")
+	}
+}
+
+func TestSubstationTracing(t *testing.T) {
+	ctx := context.Background()
+
+	// Spans are exported to an OTLP receiver.
+	spans := make(chan struct{}, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			spans <- struct{}{}
+		}
+	}))
+	defer receiver.Close()
+
+	// The trace context is propagated to HTTP requests made by transforms.
+	headers := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{"b":"c"}`))
+	}))
+	defer srv.Close()
+
+	cfg := substation.Config{
+		Transforms: []config.Config{
+			{
+				Type: "enrich_http_get",
+				Settings: map[string]interface{}{
+					"url": srv.URL,
+					"object": map[string]interface{}{
+						"target_key": "b",
+					},
+				},
+			},
+		},
+		Tracing: &substation.Tracing{
+			Endpoint: strings.TrimPrefix(receiver.URL, "http://"),
+			Insecure: true,
+		},
+	}
+
+	sub, err := substation.New(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []*message.Message{
+		message.New().SetData([]byte(`{"a":"b"}`)),
+		message.New().AsControl(),
+	}
+
+	if _, err := sub.Transform(ctx, msg...); err != nil {
+		t.Fatal(err)
+	}
+
+	if h := <-headers; h == "" {
+		t.Error("expected traceparent header")
+	}
+
+	select {
+	case <-spans:
+	case <-time.After(5 * time.Second):
+		t.Error("expected spans to be exported")
+	}
+}
@@ -0,0 +1,162 @@
+package substation
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+	"github.com/brexhq/substation/v2/transform"
+
+	"github.com/brexhq/substation/v2/internal/metrics"
+)
+
+// Telemetry configures metrics that are generated for every transform. Each
+// metric has the TransformID and TransformType attributes, which identify the
+// transform by its ID and type.
+//
+// These metrics are generated when a ctrl message is received by the transform:
+//
+// - TransformMessagesIn: number of messages received by the transform
+//
+// - TransformMessagesOut: number of messages returned by the transform
+//
+// - TransformBytesIn: number of bytes received by the transform
+//
+// - TransformBytesOut: number of bytes returned by the transform
+//
+// - TransformErrors: number of errors returned by the transform
+//
+// - TransformDuration: total amount of time spent transforming messages
+type Telemetry struct {
+	// Attributes are added to every metric.
+	Attributes map[string]string `json:"attributes"`
+	// Destination is the metrics destination that metrics are sent to (internal/metrics).
+	Destination config.Config `json:"destination"`
+}
+
+func newTelemetryTransformer(cfg config.Config, tf transform.Transformer, gen metrics.Generator, attr map[string]string) *telemetryTransformer {
+	// Transforms use their type as the default ID.
+	id := cfg.Type
+	if v, ok := cfg.Settings["id"].(string); ok && v != "" {
+		id = v
+	}
+
+	t := &telemetryTransformer{
+		tf:  tf,
+		gen: gen,
+		attributes: map[string]string{
+			"TransformID":   id,
+			"TransformType": cfg.Type,
+		},
+	}
+
+	for k, v := range attr {
+		t.attributes[k] = v
+	}
+
+	return t
+}
+
+// telemetryTransformer wraps a transform and generates metrics.
+type telemetryTransformer struct {
+	tf         transform.Transformer
+	gen        metrics.Generator
+	attributes map[string]string
+
+	msgsIn   uint64
+	msgsOut  uint64
+	bytesIn  uint64
+	bytesOut uint64
+	errors   uint64
+	// duration is the total time spent transforming messages, in nanoseconds.
+	duration uint64
+}
+
+func (t *telemetryTransformer) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	if msg.IsControl() {
+		msgs, err := t.tf.Transform(ctx, msg)
+		if err != nil {
+			atomic.AddUint64(&t.errors, 1)
+		}
+
+		// Messages that are released by a ctrl message (e.g., batches) are
+		// counted, but the ctrl message is not.
+		for _, m := range msgs {
+			if !m.IsControl() {
+				atomic.AddUint64(&t.msgsOut, 1)
+				atomic.AddUint64(&t.bytesOut, uint64(len(m.Data())))
+			}
+		}
+
+		if genErr := t.generate(ctx); genErr != nil && err == nil {
+			return nil, genErr
+		}
+
+		return msgs, err
+	}
+
+	atomic.AddUint64(&t.msgsIn, 1)
+	atomic.AddUint64(&t.bytesIn, uint64(len(msg.Data())))
+
+	start := time.Now()
+	msgs, err := t.tf.Transform(ctx, msg)
+	atomic.AddUint64(&t.duration, uint64(time.Since(start)))
+
+	if err != nil {
+		atomic.AddUint64(&t.errors, 1)
+	}
+
+	for _, m := range msgs {
+		if !m.IsControl() {
+			atomic.AddUint64(&t.msgsOut, 1)
+			atomic.AddUint64(&t.bytesOut, uint64(len(m.Data())))
+		}
+	}
+
+	return msgs, err
+}
+
+// generate sends the counters to the destination and resets them.
+func (t *telemetryTransformer) generate(ctx context.Context) error {
+	for _, m := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"TransformMessagesIn", &t.msgsIn},
+		{"TransformMessagesOut", &t.msgsOut},
+		{"TransformBytesIn", &t.bytesIn},
+		{"TransformBytesOut", &t.bytesOut},
+		{"TransformErrors", &t.errors},
+	} {
+		if err := t.gen.Generate(ctx, metrics.Data{
+			Name:       m.name,
+			Value:      atomic.SwapUint64(m.value, 0),
+			Attributes: t.attributes,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := t.gen.Generate(ctx, metrics.Data{
+		Name:       "TransformDuration",
+		Value:      time.Duration(atomic.SwapUint64(&t.duration, 0)),
+		Attributes: t.attributes,
+	}); err != nil {
+		return err
+	}
+
+	// Generators that buffer metrics are flushed so that metrics are not lost
+	// if the application is frozen (e.g., AWS Lambda).
+	return metrics.Flush(ctx, t.gen)
+}
+
+func (t *telemetryTransformer) String() string {
+	if s, ok := t.tf.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package substation
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+	"github.com/brexhq/substation/v2/transform"
+
+	"github.com/brexhq/substation/v2/internal/secrets"
+	"github.com/brexhq/substation/v2/internal/tracing"
+)
+
+// Tracing configures OpenTelemetry spans that are created for every transform and
+// exported using the OpenTelemetry Protocol (OTLP). Each span has the
+// substation.transform.id and substation.transform.type attributes, which identify
+// the transform by its ID and type.
+//
+// If tracing is enabled, then HTTP requests made by transforms (e.g., enrich_http_get,
+// send_http_post) propagate the trace context to the server and AWS API calls create
+// spans.
+type Tracing struct {
+	// Protocol is the OTLP transport protocol. Must be one of:
+	//
+	// - http: OTLP/HTTP with protobuf encoding
+	//
+	// - grpc: OTLP/gRPC
+	//
+	// This is optional and defaults to http.
+	Protocol string `json:"protocol"`
+	// Endpoint is the host and port of the OTLP receiver (e.g., localhost:4318).
+	//
+	// This is optional and defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment
+	// variable or the default endpoint for the protocol.
+	Endpoint string `json:"endpoint"`
+	// Insecure disables TLS.
+	//
+	// This is optional and defaults to false.
+	Insecure bool `json:"insecure"`
+	// Headers are sent with every export request (e.g., API keys).
+	//
+	// This is optional and defaults to no headers.
+	Headers map[string]string `json:"headers"`
+	// SampleRate is the fraction of messages that are traced, between 0 and 1.
+	//
+	// This is optional and defaults to 1 (every message is traced).
+	SampleRate float64 `json:"sample_rate"`
+}
+
+// trace transforms messages in a span. If a ctrl message is transformed, then
+// spans are exported.
+func (s *Substation) trace(ctx context.Context, msg ...*message.Message) ([]*message.Message, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "substation")
+
+	msgs, err := transform.Apply(ctx, s.tforms, msg...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+
+	for _, m := range msg {
+		if m.IsControl() {
+			if flushErr := tracing.Flush(ctx); flushErr != nil && err == nil {
+				return nil, fmt.Errorf("substation: %v", flushErr)
+			}
+
+			break
+		}
+	}
+
+	return msgs, err
+}
+
+func newTracingTransformer(cfg config.Config, tf transform.Transformer) *tracingTransformer {
+	// Transforms use their type as the default ID.
+	id := cfg.Type
+	if v, ok := cfg.Settings["id"].(string); ok && v != "" {
+		id = v
+	}
+
+	return &tracingTransformer{
+		tf:   tf,
+		name: id,
+		attributes: []attribute.KeyValue{
+			attribute.String("substation.transform.id", id),
+			attribute.String("substation.transform.type", cfg.Type),
+		},
+	}
+}
+
+// tracingTransformer wraps a transform and creates a span for every call to
+// Transform.
+type tracingTransformer struct {
+	tf         transform.Transformer
+	name       string
+	attributes []attribute.KeyValue
+}
+
+func (t *tracingTransformer) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	ctx, span := tracing.Tracer().Start(ctx, t.name,
+		trace.WithAttributes(t.attributes...),
+		trace.WithAttributes(attribute.Bool("substation.message.control", msg.IsControl())),
+	)
+	defer span.End()
+
+	msgs, err := t.tf.Transform(ctx, msg)
+	if err != nil {
+		// Errors are exported, so secrets are removed.
+		rErr := secrets.RedactError(err)
+		span.RecordError(rErr)
+		span.SetStatus(codes.Error, rErr.Error())
+	}
+
+	return msgs, err
+}
+
+func (t *tracingTransformer) String() string {
+	if s, ok := t.tf.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return ""
+}
@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	gohttp "net/http"
+	"sync"
 
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/aggregate"
 	iconfig "github.com/brexhq/substation/v2/internal/config"
 )
 
@@ -14,6 +18,11 @@ import (
 const enrichHTTPInterp = `${DATA}`
 
 type enrichDNSConfig struct {
+	// Cache stores the results of DNS lookups in memory.
+	//
+	// This is optional and has no default.
+	Cache *iconfig.Cache `json:"cache"`
+
 	ID      string          `json:"id"`
 	Object  iconfig.Object  `json:"object"`
 	Request iconfig.Request `json:"request"`
@@ -59,3 +68,60 @@ func enrichHTTPParseResponse(resp *gohttp.Response) ([]byte, error) {
 
 	return dst.Bytes(), nil
 }
+
+// enrichKVStoreBuffer stores messages so that KV store items can be retrieved
+// and set in batches.
+type enrichKVStoreBuffer struct {
+	mu   sync.Mutex
+	agg  *aggregate.Aggregate
+	msgs []*message.Message
+}
+
+func newEnrichKVStoreBuffer(cfg iconfig.Batch) (*enrichKVStoreBuffer, error) {
+	agg, err := aggregate.New(aggregate.Config{
+		Count:    cfg.Count,
+		Size:     cfg.Size,
+		Duration: cfg.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrichKVStoreBuffer{agg: agg}, nil
+}
+
+// add buffers a message. If the buffer is full, then the buffered messages are
+// returned and the buffer is reset to contain only the new message.
+func (b *enrichKVStoreBuffer) add(msg *message.Message) ([]*message.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok := b.agg.Add("", msg.Data()); ok {
+		b.msgs = append(b.msgs, msg)
+		return nil, nil
+	}
+
+	msgs := b.msgs
+	b.msgs = nil
+
+	// If data cannot be added after reset, then the batch is misconfigured.
+	b.agg.Reset("")
+	if ok := b.agg.Add("", msg.Data()); !ok {
+		return nil, errBatchNoMoreData
+	}
+
+	b.msgs = append(b.msgs, msg)
+	return msgs, nil
+}
+
+// drain returns all buffered messages and resets the buffer.
+func (b *enrichKVStoreBuffer) drain() []*message.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msgs := b.msgs
+	b.msgs = nil
+	b.agg.Reset("")
+
+	return msgs
+}
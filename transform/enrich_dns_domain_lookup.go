@@ -11,9 +11,11 @@ import (
 
 	"github.com/brexhq/substation/v2/config"
 	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/kv"
 )
 
-func newEnrichDNSDomainLookup(_ context.Context, cfg config.Config) (*enrichDNSDomainLookup, error) {
+func newEnrichDNSDomainLookup(ctx context.Context, cfg config.Config) (*enrichDNSDomainLookup, error) {
 	conf := enrichDNSConfig{}
 	if err := conf.Decode(cfg.Settings); err != nil {
 		return nil, fmt.Errorf("transform enrich_dns_domain_lookup: %v", err)
@@ -39,6 +41,15 @@ func newEnrichDNSDomainLookup(_ context.Context, cfg config.Config) (*enrichDNSD
 		timeout:  dur,
 	}
 
+	if conf.Cache != nil {
+		c, err := kv.NewCache(ctx, *conf.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.cache = c
+	}
+
 	return &tf, nil
 }
 
@@ -48,6 +59,7 @@ type enrichDNSDomainLookup struct {
 
 	resolver net.Resolver
 	timeout  time.Duration
+	cache    *kv.Cache
 }
 
 // Transform performs a DNS lookup on a message.
@@ -56,12 +68,20 @@ func (tf *enrichDNSDomainLookup) Transform(ctx context.Context, msg *message.Mes
 	defer cancel() // important to avoid a resource leak
 
 	if msg.IsControl() {
+		if tf.cache == nil {
+			return []*message.Message{msg}, nil
+		}
+
+		if err := tf.cache.GenerateMetrics(ctx); err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
 		return []*message.Message{msg}, nil
 	}
 
 	if !tf.isObj {
 		str := string(msg.Data())
-		names, err := tf.resolver.LookupHost(resolverCtx, str)
+		names, err := tf.lookup(resolverCtx, str)
 		if err != nil {
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
@@ -77,7 +97,7 @@ func (tf *enrichDNSDomainLookup) Transform(ctx context.Context, msg *message.Mes
 		return []*message.Message{msg}, nil
 	}
 
-	names, err := tf.resolver.LookupHost(resolverCtx, value.String())
+	names, err := tf.lookup(resolverCtx, value.String())
 	if err != nil {
 		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 	}
@@ -89,6 +109,23 @@ func (tf *enrichDNSDomainLookup) Transform(ctx context.Context, msg *message.Mes
 	return []*message.Message{msg}, nil
 }
 
+// lookup performs a DNS lookup and optionally caches the result.
+func (tf *enrichDNSDomainLookup) lookup(ctx context.Context, name string) ([]string, error) {
+	if tf.cache == nil {
+		return tf.resolver.LookupHost(ctx, name)
+	}
+
+	v, err := tf.cache.Get(ctx, name, func(ctx context.Context, name string) (interface{}, error) {
+		return tf.resolver.LookupHost(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, _ := v.([]string)
+	return res, nil
+}
+
 func (tf *enrichDNSDomainLookup) String() string {
 	b, _ := json.Marshal(tf.conf)
 	return string(b)
@@ -13,6 +13,7 @@ import (
 
 	iconfig "github.com/brexhq/substation/v2/internal/config"
 	"github.com/brexhq/substation/v2/internal/http"
+	"github.com/brexhq/substation/v2/internal/kv"
 	"github.com/brexhq/substation/v2/internal/secrets"
 )
 
@@ -28,6 +29,10 @@ type enrichHTTPGetConfig struct {
 	//
 	// This is optional and has no default.
 	Headers map[string]string `json:"headers"`
+	// Cache stores responses in memory using the interpolated URL as the key.
+	//
+	// This is optional and has no default.
+	Cache *iconfig.Cache `json:"cache"`
 
 	ID     string         `json:"id"`
 	Object iconfig.Object `json:"object"`
@@ -77,6 +82,15 @@ func newEnrichHTTPGet(ctx context.Context, cfg config.Config) (*enrichHTTPGet, e
 		})
 	}
 
+	if conf.Cache != nil {
+		c, err := kv.NewCache(ctx, *conf.Cache)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.cache = c
+	}
+
 	return &tf, nil
 }
 
@@ -86,10 +100,19 @@ type enrichHTTPGet struct {
 	// client is safe for concurrent use.
 	client  http.HTTP
 	headers []http.Header
+	cache   *kv.Cache
 }
 
 func (tf *enrichHTTPGet) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
 	if msg.IsControl() {
+		if tf.cache == nil {
+			return []*message.Message{msg}, nil
+		}
+
+		if err := tf.cache.GenerateMetrics(ctx); err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
 		return []*message.Message{msg}, nil
 	}
 
@@ -124,17 +147,11 @@ func (tf *enrichHTTPGet) Transform(ctx context.Context, msg *message.Message) ([
 		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 	}
 
-	// resp.Body is closed by enrichHTTPParseResponse.
-	resp, err := tf.client.Get(ctx, url, tf.headers...)
+	parsed, err := tf.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 	}
 
-	parsed, err := enrichHTTPParseResponse(resp)
-	if err != nil {
-		return nil, fmt.Errorf("transform	%s: %v", tf.conf.ID, err)
-	}
-
 	// If TargetKey is set, then the response body is stored in the message.
 	// Otherwise, the response body overwrites the message data.
 	if tf.conf.Object.TargetKey != "" {
@@ -149,7 +166,36 @@ func (tf *enrichHTTPGet) Transform(ctx context.Context, msg *message.Message) ([
 	return []*message.Message{msg}, nil
 }
 
+// get retrieves and parses the response from a URL and optionally caches the result.
+func (tf *enrichHTTPGet) get(ctx context.Context, url string) ([]byte, error) {
+	fn := func(ctx context.Context, url string) (interface{}, error) {
+		// resp.Body is closed by enrichHTTPParseResponse.
+		resp, err := tf.client.Get(ctx, url, tf.headers...)
+		if err != nil {
+			return nil, err
+		}
+
+		return enrichHTTPParseResponse(resp)
+	}
+
+	if tf.cache == nil {
+		v, err := fn(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		return v.([]byte), nil
+	}
+
+	v, err := tf.cache.Get(ctx, url, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
 func (tf *enrichHTTPGet) String() string {
 	b, _ := json.Marshal(tf.conf)
-	return string(b)
+	return secrets.Redact(string(b))
 }
@@ -168,5 +168,5 @@ func (tf *enrichHTTPPost) Transform(ctx context.Context, msg *message.Message) (
 
 func (tf *enrichHTTPPost) String() string {
 	b, _ := json.Marshal(tf.conf)
-	return string(b)
+	return secrets.Redact(string(b))
 }
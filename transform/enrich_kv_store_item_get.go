@@ -25,6 +25,13 @@ type enrichKVStoreItemGetConfig struct {
 	//
 	// This is optional and defaults to false (KV store is not closed).
 	CloseKVStore bool `json:"close_kv_store"`
+	// Batch buffers messages and retrieves items from the KV store in batches.
+	// Buffered messages are enriched when the batch is full or when a control
+	// message is received. KV stores that support batch operations (e.g.,
+	// aws_dynamodb) retrieve all items in the batch with fewer requests.
+	//
+	// This is optional and defaults to retrieving items for each message.
+	Batch *iconfig.Batch `json:"batch"`
 
 	ID      string         `json:"id"`
 	Object  iconfig.Object `json:"object"`
@@ -75,25 +82,70 @@ func newEnrichKVStoreItemGet(_ context.Context, cfg config.Config) (*enrichKVSto
 		kvStore: kvStore,
 	}
 
+	if conf.Batch != nil {
+		buf, err := newEnrichKVStoreBuffer(*conf.Batch)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.buf = buf
+	}
+
 	return &tf, nil
 }
 
 type enrichKVStoreItemGet struct {
 	conf    enrichKVStoreItemGetConfig
 	kvStore kv.Storer
+	buf     *enrichKVStoreBuffer
 }
 
 func (tf *enrichKVStoreItemGet) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
 	if msg.IsControl() {
+		var msgs []*message.Message
+		if tf.buf != nil {
+			out, err := tf.getBatch(ctx, tf.buf.drain())
+			if err != nil {
+				return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+			}
+
+			msgs = out
+		}
+
+		// Stores that are wrapped with a cache generate cache metrics.
+		if m, ok := tf.kvStore.(kv.MetricsGenerator); ok {
+			if err := m.GenerateMetrics(ctx); err != nil {
+				return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+			}
+		}
+
 		if !tf.conf.CloseKVStore {
-			return []*message.Message{msg}, nil
+			return append(msgs, msg), nil
 		}
 
 		if err := tf.kvStore.Close(); err != nil {
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
 
-		return []*message.Message{msg}, nil
+		return append(msgs, msg), nil
+	}
+
+	if tf.buf != nil {
+		msgs, err := tf.buf.add(msg)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
+		if len(msgs) == 0 {
+			return nil, nil
+		}
+
+		msgs, err = tf.getBatch(ctx, msgs)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
+		return msgs, nil
 	}
 
 	if !tf.kvStore.IsEnabled() {
@@ -102,16 +154,11 @@ func (tf *enrichKVStoreItemGet) Transform(ctx context.Context, msg *message.Mess
 		}
 	}
 
-	value := msg.GetValue(tf.conf.Object.SourceKey)
-	if !value.Exists() {
+	key, ok := tf.key(msg)
+	if !ok {
 		return []*message.Message{msg}, nil
 	}
 
-	key := value.String()
-	if tf.conf.Prefix != "" {
-		key = fmt.Sprint(tf.conf.Prefix, ":", key)
-	}
-
 	v, err := tf.kvStore.Get(ctx, key)
 	if err != nil {
 		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
@@ -124,6 +171,61 @@ func (tf *enrichKVStoreItemGet) Transform(ctx context.Context, msg *message.Mess
 	return []*message.Message{msg}, nil
 }
 
+// getBatch retrieves items for a batch of messages from the KV store and returns
+// the enriched messages.
+func (tf *enrichKVStoreItemGet) getBatch(ctx context.Context, msgs []*message.Message) ([]*message.Message, error) {
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+
+	if !tf.kvStore.IsEnabled() {
+		if err := tf.kvStore.Setup(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var keys []string
+	for _, msg := range msgs {
+		if key, ok := tf.key(msg); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	items, err := kv.GetBatch(ctx, tf.kvStore, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, msg := range msgs {
+		key, ok := tf.key(msg)
+		if !ok {
+			continue
+		}
+
+		if err := msg.SetValue(tf.conf.Object.TargetKey, items[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return msgs, nil
+}
+
+// key returns the KV store key for a message. If the message does not
+// contain a key, then false is returned.
+func (tf *enrichKVStoreItemGet) key(msg *message.Message) (string, bool) {
+	value := msg.GetValue(tf.conf.Object.SourceKey)
+	if !value.Exists() {
+		return "", false
+	}
+
+	key := value.String()
+	if tf.conf.Prefix != "" {
+		key = fmt.Sprint(tf.conf.Prefix, ":", key)
+	}
+
+	return key, true
+}
+
 func (tf *enrichKVStoreItemGet) String() string {
 	b, _ := json.Marshal(tf.conf)
 	return string(b)
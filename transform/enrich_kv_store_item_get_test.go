@@ -0,0 +1,97 @@
+//go:build !wasm
+
+package transform
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/kv"
+)
+
+var _ Transformer = &enrichKVStoreItemGet{}
+
+func TestEnrichKVStoreItemGetBatch(t *testing.T) {
+	ctx := context.TODO()
+
+	// Stores with the same configuration are shared, so the transform reads
+	// the items set by the test.
+	kvCfg := config.Config{
+		Type: "memory",
+		Settings: map[string]interface{}{
+			"capacity": 101,
+		},
+	}
+
+	store, err := kv.Get(kvCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{"a": "1", "b": "2", "c": "3"} {
+		if err := store.Set(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tf, err := newEnrichKVStoreItemGet(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "key",
+				"target_key": "value",
+			},
+			"batch": map[string]interface{}{
+				"count": 2,
+			},
+			"kv_store": kvCfg,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		msg      *message.Message
+		expected []string
+	}{
+		{"buffered", message.New().SetData([]byte(`{"key":"a"}`)), nil},
+		{"full", message.New().SetData([]byte(`{"key":"b"}`)), nil},
+		// The batch is full, so the buffered messages are enriched and returned.
+		{"flush on size", message.New().SetData([]byte(`{"key":"c"}`)), []string{
+			`{"key":"a","value":"1"}`,
+			`{"key":"b","value":"2"}`,
+		}},
+		{"missing key", message.New().SetData([]byte(`{"key":"d"}`)), nil},
+		// The control message flushes the remaining messages.
+		{"flush on control", message.New().AsControl(), []string{
+			`{"key":"c","value":"3"}`,
+			`{"key":"d","value":null}`,
+			``,
+		}},
+	}
+
+	for _, test := range tests {
+		msgs, err := tf.Transform(ctx, test.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out []string
+		for _, m := range msgs {
+			out = append(out, string(m.Data()))
+		}
+
+		if !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, out)
+		}
+	}
+}
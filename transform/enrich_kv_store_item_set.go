@@ -5,6 +5,7 @@ package transform
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -23,6 +24,11 @@ type enrichKVStoreItemSetObjectConfig struct {
 	//
 	// This is optional and defaults to using no TTL when setting items into the store.
 	TTLKey string `json:"ttl_key"`
+	// CompareKey retrieves a value from an object that is compared to the value
+	// in the KV store when SetCondition is "equal_to".
+	//
+	// This is optional and has no default.
+	CompareKey string `json:"compare_key"`
 
 	iconfig.Object
 }
@@ -48,6 +54,25 @@ type enrichKVStoreItemSetConfig struct {
 	//
 	// This is optional and defaults to false (KV store is not closed).
 	CloseKVStore bool `json:"close_kv_store"`
+	// SetCondition determines if items are set based on the items that are already
+	// in the KV store. If the condition is not met, then the message is not changed.
+	// Must be one of:
+	//
+	// - not_exists: the item is set if the key does not exist in the store.
+	//
+	// - equal_to: the item is set if the key exists and the value in the store is
+	// equal to the value from Object.CompareKey (compare-and-set).
+	//
+	// The KV store must support conditional writes (e.g., aws_dynamodb, memory). This
+	// is optional and defaults to setting items unconditionally.
+	SetCondition string `json:"set_condition"`
+	// Batch buffers messages and sets items into the KV store in batches. Buffered
+	// messages are set when the batch is full or when a control message is received.
+	// KV stores that support batch operations (e.g., aws_dynamodb) set all items in
+	// the batch with fewer requests. This cannot be used with SetCondition.
+	//
+	// This is optional and defaults to setting items for each message.
+	Batch *iconfig.Batch `json:"batch"`
 
 	ID      string                           `json:"id"`
 	Object  enrichKVStoreItemSetObjectConfig `json:"object"`
@@ -71,6 +96,20 @@ func (c *enrichKVStoreItemSetConfig) Validate() error {
 		return fmt.Errorf("kv_store: %v", iconfig.ErrMissingRequiredOption)
 	}
 
+	switch c.SetCondition {
+	case "", "not_exists":
+	case "equal_to":
+		if c.Object.CompareKey == "" {
+			return fmt.Errorf("object_compare_key: %v", iconfig.ErrMissingRequiredOption)
+		}
+	default:
+		return fmt.Errorf("set_condition: %v", iconfig.ErrInvalidOption)
+	}
+
+	if c.SetCondition != "" && c.Batch != nil {
+		return fmt.Errorf("set_condition: %v", iconfig.ErrInvalidOption)
+	}
+
 	return nil
 }
 
@@ -93,6 +132,10 @@ func newEnrichKVStoreItemSet(_ context.Context, cfg config.Config) (*enrichKVSto
 		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
 	}
 
+	if conf.SetCondition != "" && !kv.IsConditional(kvStore) {
+		return nil, fmt.Errorf("transform %s: kv_store %s: %v", conf.ID, conf.KVStore.Type, iconfig.ErrInvalidOption)
+	}
+
 	if conf.TTLOffset == "" {
 		conf.TTLOffset = "0s"
 	}
@@ -108,6 +151,15 @@ func newEnrichKVStoreItemSet(_ context.Context, cfg config.Config) (*enrichKVSto
 		ttl:     int64(dur.Seconds()),
 	}
 
+	if conf.Batch != nil {
+		buf, err := newEnrichKVStoreBuffer(*conf.Batch)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.buf = buf
+	}
+
 	return &tf, nil
 }
 
@@ -115,19 +167,45 @@ type enrichKVStoreItemSet struct {
 	conf    enrichKVStoreItemSetConfig
 	kvStore kv.Storer
 	ttl     int64
+	buf     *enrichKVStoreBuffer
 }
 
 func (tf *enrichKVStoreItemSet) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
 	if msg.IsControl() {
+		var msgs []*message.Message
+		if tf.buf != nil {
+			msgs = tf.buf.drain()
+			if err := tf.setBatch(ctx, msgs); err != nil {
+				return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+			}
+		}
+
 		if !tf.conf.CloseKVStore {
-			return []*message.Message{msg}, nil
+			return append(msgs, msg), nil
 		}
 
 		if err := tf.kvStore.Close(); err != nil {
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
 
-		return []*message.Message{msg}, nil
+		return append(msgs, msg), nil
+	}
+
+	if tf.buf != nil {
+		msgs, err := tf.buf.add(msg)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
+		if len(msgs) == 0 {
+			return nil, nil
+		}
+
+		if err := tf.setBatch(ctx, msgs); err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
+		return msgs, nil
 	}
 
 	if !tf.kvStore.IsEnabled() {
@@ -136,39 +214,25 @@ func (tf *enrichKVStoreItemSet) Transform(ctx context.Context, msg *message.Mess
 		}
 	}
 
-	value := msg.GetValue(tf.conf.Object.SourceKey)
-	if !value.Exists() {
+	item, ok := tf.item(msg)
+	if !ok {
 		return []*message.Message{msg}, nil
 	}
 
-	key := value.String()
-	if tf.conf.Prefix != "" {
-		key = fmt.Sprint(tf.conf.Prefix, ":", key)
-	}
-
-	//nolint: nestif // ignore nesting complexity
-	if tf.conf.Object.TTLKey != "" && tf.ttl != 0 {
-		value := msg.GetValue(tf.conf.Object.TTLKey)
-		ttl := truncateTTL(value) + tf.ttl
-
-		if err := tf.kvStore.SetWithTTL(ctx, key, msg.GetValue(tf.conf.Object.TargetKey).Value(), ttl); err != nil {
+	if tf.conf.SetCondition != "" {
+		if err := tf.setWithCondition(ctx, msg, item); err != nil {
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
-	} else if tf.conf.Object.TTLKey != "" {
-		value := msg.GetValue(tf.conf.Object.TTLKey)
-		ttl := truncateTTL(value)
 
-		if err := tf.kvStore.SetWithTTL(ctx, key, msg.GetValue(tf.conf.Object.TargetKey).Value(), ttl); err != nil {
-			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
-		}
-	} else if tf.ttl != 0 {
-		ttl := time.Now().Add(time.Duration(tf.ttl) * time.Second).Unix()
+		return []*message.Message{msg}, nil
+	}
 
-		if err := tf.kvStore.SetWithTTL(ctx, key, msg.GetValue(tf.conf.Object.TargetKey).Value(), ttl); err != nil {
+	if item.TTL != 0 {
+		if err := tf.kvStore.SetWithTTL(ctx, item.Key, item.Value, item.TTL); err != nil {
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
 	} else {
-		if err := tf.kvStore.Set(ctx, key, msg.GetValue(tf.conf.Object.TargetKey).Value()); err != nil {
+		if err := tf.kvStore.Set(ctx, item.Key, item.Value); err != nil {
 			return nil, fmt.Errorf("transform	%s: %v", tf.conf.ID, err)
 		}
 	}
@@ -176,6 +240,83 @@ func (tf *enrichKVStoreItemSet) Transform(ctx context.Context, msg *message.Mess
 	return []*message.Message{msg}, nil
 }
 
+// setWithCondition sets an item into the KV store if the condition is met. Items
+// that do not meet the condition are ignored.
+func (tf *enrichKVStoreItemSet) setWithCondition(ctx context.Context, msg *message.Message, item kv.Item) error {
+	// Stores that do not support conditional writes are rejected when the
+	// transform is created.
+	s := tf.kvStore.(kv.ConditionalStorer)
+
+	var err error
+	switch tf.conf.SetCondition {
+	case "not_exists":
+		err = s.SetIfNotExistsWithTTL(ctx, item.Key, item.Value, item.TTL)
+	case "equal_to":
+		old := msg.GetValue(tf.conf.Object.CompareKey).Value()
+		err = s.CompareAndSetWithTTL(ctx, item.Key, old, item.Value, item.TTL)
+	}
+
+	if errors.Is(err, kv.ErrConditionFailed) {
+		return nil
+	}
+
+	return err
+}
+
+// setBatch sets items for a batch of messages into the KV store.
+func (tf *enrichKVStoreItemSet) setBatch(ctx context.Context, msgs []*message.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	if !tf.kvStore.IsEnabled() {
+		if err := tf.kvStore.Setup(ctx); err != nil {
+			return err
+		}
+	}
+
+	var items []kv.Item
+	for _, msg := range msgs {
+		if item, ok := tf.item(msg); ok {
+			items = append(items, item)
+		}
+	}
+
+	return kv.SetBatch(ctx, tf.kvStore, items)
+}
+
+// item returns the KV store item for a message. If the message does not
+// contain a key, then false is returned.
+func (tf *enrichKVStoreItemSet) item(msg *message.Message) (kv.Item, bool) {
+	value := msg.GetValue(tf.conf.Object.SourceKey)
+	if !value.Exists() {
+		return kv.Item{}, false
+	}
+
+	key := value.String()
+	if tf.conf.Prefix != "" {
+		key = fmt.Sprint(tf.conf.Prefix, ":", key)
+	}
+
+	item := kv.Item{
+		Key:   key,
+		Value: msg.GetValue(tf.conf.Object.TargetKey).Value(),
+	}
+
+	switch {
+	case tf.conf.Object.TTLKey != "" && tf.ttl != 0:
+		value := msg.GetValue(tf.conf.Object.TTLKey)
+		item.TTL = truncateTTL(value) + tf.ttl
+	case tf.conf.Object.TTLKey != "":
+		value := msg.GetValue(tf.conf.Object.TTLKey)
+		item.TTL = truncateTTL(value)
+	case tf.ttl != 0:
+		item.TTL = time.Now().Add(time.Duration(tf.ttl) * time.Second).Unix()
+	}
+
+	return item, true
+}
+
 func (tf *enrichKVStoreItemSet) String() string {
 	b, _ := json.Marshal(tf.conf)
 	return string(b)
@@ -0,0 +1,248 @@
+//go:build !wasm
+
+package transform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/kv"
+)
+
+var _ Transformer = &enrichKVStoreItemSet{}
+
+func TestEnrichKVStoreItemSet(t *testing.T) {
+	ctx := context.TODO()
+
+	// Stores with the same configuration are shared, so the test reads the
+	// items set by the transform.
+	kvCfg := config.Config{
+		Type: "memory",
+		Settings: map[string]interface{}{
+			"capacity": 102,
+		},
+	}
+
+	store, err := kv.Get(kvCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		// existing is the value of the key before the transform runs.
+		existing interface{}
+		data     []byte
+		expected interface{}
+	}{
+		{
+			"set",
+			nil,
+			"a",
+			[]byte(`{"key":"k","value":"b"}`),
+			"b",
+		},
+		{
+			"not_exists",
+			map[string]interface{}{"set_condition": "not_exists"},
+			nil,
+			[]byte(`{"key":"k","value":"b"}`),
+			"b",
+		},
+		{
+			"not_exists with existing value",
+			map[string]interface{}{"set_condition": "not_exists"},
+			"a",
+			[]byte(`{"key":"k","value":"b"}`),
+			"a",
+		},
+		{
+			"equal_to",
+			map[string]interface{}{"set_condition": "equal_to"},
+			"a",
+			[]byte(`{"key":"k","value":"b","old":"a"}`),
+			"b",
+		},
+		{
+			"equal_to with different value",
+			map[string]interface{}{"set_condition": "equal_to"},
+			"c",
+			[]byte(`{"key":"k","value":"b","old":"a"}`),
+			"c",
+		},
+		{
+			"equal_to with missing value",
+			map[string]interface{}{"set_condition": "equal_to"},
+			nil,
+			[]byte(`{"key":"k","value":"b","old":"a"}`),
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prefix := "item_set:" + test.name
+			key := prefix + ":k"
+
+			if test.existing != nil {
+				if err := store.Set(ctx, key, test.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			settings := map[string]interface{}{
+				"prefix": prefix,
+				"object": map[string]interface{}{
+					"source_key":  "key",
+					"target_key":  "value",
+					"compare_key": "old",
+				},
+				"kv_store": kvCfg,
+			}
+			for k, v := range test.settings {
+				settings[k] = v
+			}
+
+			tf, err := newEnrichKVStoreItemSet(ctx, config.Config{Settings: settings})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msgs, err := tf.Transform(ctx, message.New().SetData(test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(msgs) != 1 || string(msgs[0].Data()) != string(test.data) {
+				t.Errorf("expected %s, got %v", test.data, msgs)
+			}
+
+			v, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestEnrichKVStoreItemSetBatch(t *testing.T) {
+	ctx := context.TODO()
+
+	kvCfg := config.Config{
+		Type: "memory",
+		Settings: map[string]interface{}{
+			"capacity": 103,
+		},
+	}
+
+	store, err := kv.Get(kvCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := newEnrichKVStoreItemSet(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"object": map[string]interface{}{
+				"source_key": "key",
+				"target_key": "value",
+			},
+			"batch": map[string]interface{}{
+				"count": 2,
+			},
+			"kv_store": kvCfg,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		msg  *message.Message
+		// count is the number of messages returned by the transform.
+		count    int
+		expected map[string]interface{}
+	}{
+		{"buffered", message.New().SetData([]byte(`{"key":"a","value":"1"}`)), 0, map[string]interface{}{"a": nil}},
+		{"full", message.New().SetData([]byte(`{"key":"b","value":"2"}`)), 0, map[string]interface{}{"a": nil, "b": nil}},
+		// The batch is full, so the buffered messages are written.
+		{"flush on size", message.New().SetData([]byte(`{"key":"c","value":"3"}`)), 2, map[string]interface{}{"a": "1", "b": "2", "c": nil}},
+		// The control message flushes the remaining messages.
+		{"flush on control", message.New().AsControl(), 2, map[string]interface{}{"c": "3"}},
+	}
+
+	for _, test := range tests {
+		msgs, err := tf.Transform(ctx, test.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(msgs) != test.count {
+			t.Errorf("%s: expected %d messages, got %d", test.name, test.count, len(msgs))
+		}
+
+		for k, expected := range test.expected {
+			v, err := store.Get(ctx, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != expected {
+				t.Errorf("%s: expected %v for %s, got %v", test.name, expected, k, v)
+			}
+		}
+	}
+}
+
+func TestEnrichKVStoreItemSetValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+	}{
+		{"invalid set_condition", map[string]interface{}{"set_condition": "exists"}},
+		{"equal_to without compare_key", map[string]interface{}{"set_condition": "equal_to"}},
+		{"set_condition with batch", map[string]interface{}{
+			"set_condition": "not_exists",
+			"batch":         map[string]interface{}{"count": 2},
+		}},
+		{"set_condition without conditional kv_store", map[string]interface{}{
+			"set_condition": "not_exists",
+			"kv_store":      config.Config{Type: "text_file", Settings: map[string]interface{}{"file": "a"}},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := map[string]interface{}{
+				"object": map[string]interface{}{
+					"source_key": "key",
+					"target_key": "value",
+				},
+				"kv_store": config.Config{Type: "memory"},
+			}
+			for k, v := range test.settings {
+				settings[k] = v
+			}
+
+			if _, err := newEnrichKVStoreItemSet(context.TODO(), config.Config{Settings: settings}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
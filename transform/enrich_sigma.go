@@ -0,0 +1,128 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/condition"
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/sigma"
+)
+
+type enrichSigmaConfig struct {
+	// Rules is a list of Sigma rules in YAML format. Multiple rules in a single
+	// value are separated by "---".
+	Rules []string `json:"rules"`
+	// Files is a list of locations of files that contain Sigma rules. These can
+	// be either a path on local disk, an HTTP(S) URL, or an AWS S3 URL.
+	Files []string `json:"files"`
+	// Fields maps Sigma field names to message keys (e.g., CommandLine to
+	// process.command_line).
+	//
+	// This is optional and defaults to using Sigma field names as message keys.
+	Fields map[string]string `json:"fields"`
+
+	ID     string         `json:"id"`
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *enrichSigmaConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *enrichSigmaConfig) Validate() error {
+	if len(c.Rules) == 0 && len(c.Files) == 0 {
+		return fmt.Errorf("rules: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Object.TargetKey == "" {
+		return fmt.Errorf("object_target_key: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newEnrichSigma(ctx context.Context, cfg config.Config) (*enrichSigma, error) {
+	conf := enrichSigmaConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("transform enrich_sigma: %v", err)
+	}
+
+	if conf.ID == "" {
+		conf.ID = "enrich_sigma"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	rules, err := sigma.Load(ctx, conf.Rules, conf.Files, conf.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	tf := enrichSigma{
+		conf: conf,
+	}
+
+	for _, r := range rules {
+		cnd, err := condition.New(ctx, r.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: rule %s: %v", conf.ID, r.ID, err)
+		}
+
+		tf.rules = append(tf.rules, enrichSigmaRule{id: r.ID, cnd: cnd})
+	}
+
+	return &tf, nil
+}
+
+type enrichSigmaRule struct {
+	id  string
+	cnd condition.Conditioner
+}
+
+type enrichSigma struct {
+	conf enrichSigmaConfig
+
+	rules []enrichSigmaRule
+}
+
+// Transform writes the IDs of the rules that match the message to the target
+// key. If no rules match, then the message is not changed.
+func (tf *enrichSigma) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	if msg.IsControl() {
+		return []*message.Message{msg}, nil
+	}
+
+	var ids []string
+	for _, r := range tf.rules {
+		ok, err := r.cnd.Condition(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: rule %s: %v", tf.conf.ID, r.id, err)
+		}
+
+		if ok {
+			ids = append(ids, r.id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return []*message.Message{msg}, nil
+	}
+
+	if err := msg.SetValue(tf.conf.Object.TargetKey, ids); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+	}
+
+	return []*message.Message{msg}, nil
+}
+
+func (tf *enrichSigma) String() string {
+	b, _ := json.Marshal(tf.conf)
+	return string(b)
+}
@@ -0,0 +1,169 @@
+package transform
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Transformer = &enrichSigma{}
+
+var enrichSigmaTestRules = []string{`
+title: Whoami
+id: rule-1
+detection:
+  selection:
+    CommandLine|endswith: whoami
+  condition: selection
+---
+title: Command Shell
+detection:
+  selection:
+    Image|endswith: '\cmd.exe'
+  condition: selection
+`}
+
+var enrichSigmaTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected [][]byte
+}{
+	{
+		"match",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": enrichSigmaTestRules,
+				"object": map[string]interface{}{
+					"target_key": "rules",
+				},
+			},
+		},
+		[]byte(`{"Image":"C:\\cmd.exe","CommandLine":"cmd.exe /c whoami"}`),
+		[][]byte{
+			[]byte(`{"Image":"C:\\cmd.exe","CommandLine":"cmd.exe /c whoami","rules":["rule-1","Command Shell"]}`),
+		},
+	},
+	{
+		"match",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": enrichSigmaTestRules,
+				"fields": map[string]string{
+					"Image": "process.executable",
+				},
+				"object": map[string]interface{}{
+					"target_key": "rules",
+				},
+			},
+		},
+		[]byte(`{"process":{"executable":"C:\\CMD.EXE"}}`),
+		[][]byte{
+			[]byte(`{"process":{"executable":"C:\\CMD.EXE"},"rules":["Command Shell"]}`),
+		},
+	},
+	{
+		"no match",
+		config.Config{
+			Settings: map[string]interface{}{
+				"rules": enrichSigmaTestRules,
+				"object": map[string]interface{}{
+					"target_key": "rules",
+				},
+			},
+		},
+		[]byte(`{"Image":"C:\\powershell.exe"}`),
+		[][]byte{
+			[]byte(`{"Image":"C:\\powershell.exe"}`),
+		},
+	},
+}
+
+func TestEnrichSigma(t *testing.T) {
+	ctx := context.TODO()
+	for _, test := range enrichSigmaTests {
+		t.Run(test.name, func(t *testing.T) {
+			tf, err := newEnrichSigma(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg := message.New().SetData(test.test)
+			result, err := tf.Transform(ctx, msg)
+			if err != nil {
+				t.Error(err)
+			}
+
+			var data [][]byte
+			for _, c := range result {
+				data = append(data, c.Data())
+			}
+
+			if !reflect.DeepEqual(data, test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, data)
+			}
+		})
+	}
+}
+
+func benchmarkEnrichSigma(b *testing.B, tf *enrichSigma, data []byte) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		msg := message.New().SetData(data)
+		_, _ = tf.Transform(ctx, msg)
+	}
+}
+
+func BenchmarkEnrichSigma(b *testing.B) {
+	for _, test := range enrichSigmaTests {
+		tf, err := newEnrichSigma(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				benchmarkEnrichSigma(b, tf, test.test)
+			},
+		)
+	}
+}
+
+func FuzzTestEnrichSigma(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"Image":"C:\\cmd.exe","CommandLine":"cmd.exe /c whoami"}`),
+		[]byte(`{"Image":["a","b"]}`),
+		[]byte(`{"CommandLine":null}`),
+		[]byte(`whoami`),
+		[]byte(``),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	tf, err := newEnrichSigma(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"rules": enrichSigmaTestRules,
+			"object": map[string]interface{}{
+				"target_key": "rules",
+			},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := tf.Transform(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/jsonschema"
+)
+
+type formatJSONSchemaConfig struct {
+	// Schema is a JSON Schema (draft 2020-12) that is used to validate data. The
+	// format keyword is asserted (e.g., "format": "date-time" only matches timestamps).
+	Schema map[string]interface{} `json:"schema"`
+	// File contains the location of a file that contains a JSON Schema. This can
+	// be either a path on local disk, an HTTP(S) URL, or an AWS S3 URL.
+	File string `json:"file"`
+
+	ID     string         `json:"id"`
+	Object iconfig.Object `json:"object"`
+}
+
+func (c *formatJSONSchemaConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *formatJSONSchemaConfig) Validate() error {
+	if c.Schema == nil && c.File == "" {
+		return fmt.Errorf("schema: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	if c.Object.TargetKey == "" {
+		return fmt.Errorf("object_target_key: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	return nil
+}
+
+func newFormatJSONSchema(ctx context.Context, cfg config.Config) (*formatJSONSchema, error) {
+	conf := formatJSONSchemaConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("transform format_json_schema: %v", err)
+	}
+
+	if conf.ID == "" {
+		conf.ID = "format_json_schema"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	v, err := jsonschema.New(ctx, conf.Schema, conf.File)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	tf := formatJSONSchema{
+		conf:      conf,
+		validator: v,
+	}
+
+	return &tf, nil
+}
+
+type formatJSONSchema struct {
+	conf formatJSONSchemaConfig
+
+	validator *jsonschema.Validator
+}
+
+// Transform validates data and writes the list of validation errors to the
+// target key. If the data is valid, then the message is not changed.
+func (tf *formatJSONSchema) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	if msg.IsControl() {
+		return []*message.Message{msg}, nil
+	}
+
+	b := msg.Data()
+	if tf.conf.Object.SourceKey != "" {
+		// The value is encoded so that strings are validated as JSON strings.
+		// Missing values are validated as null.
+		v, err := json.Marshal(msg.GetValue(tf.conf.Object.SourceKey).Value())
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
+		b = v
+	}
+
+	errs, err := tf.validator.Validate(b)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+	}
+
+	if len(errs) == 0 {
+		return []*message.Message{msg}, nil
+	}
+
+	if err := msg.SetValue(tf.conf.Object.TargetKey, errs); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+	}
+
+	return []*message.Message{msg}, nil
+}
+
+func (tf *formatJSONSchema) String() string {
+	b, _ := json.Marshal(tf.conf)
+	return string(b)
+}
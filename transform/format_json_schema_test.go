@@ -0,0 +1,166 @@
+package transform
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Transformer = &formatJSONSchema{}
+
+var formatJSONSchemaTestSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"id"},
+	"properties": map[string]interface{}{
+		"id": map[string]interface{}{
+			"type": "integer",
+		},
+		"ts": map[string]interface{}{
+			"type":   "string",
+			"format": "date-time",
+		},
+	},
+}
+
+var formatJSONSchemaTests = []struct {
+	name     string
+	cfg      config.Config
+	test     []byte
+	expected [][]byte
+}{
+	{
+		"valid",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+				"object": map[string]interface{}{
+					"target_key": "errors",
+				},
+			},
+		},
+		[]byte(`{"id":1,"ts":"2024-01-01T00:00:00Z"}`),
+		[][]byte{
+			[]byte(`{"id":1,"ts":"2024-01-01T00:00:00Z"}`),
+		},
+	},
+	{
+		"invalid",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+				"object": map[string]interface{}{
+					"target_key": "errors",
+				},
+			},
+		},
+		[]byte(`{"ts":"yesterday"}`),
+		[][]byte{
+			[]byte(`{"ts":"yesterday","errors":["at '': missing property 'id'","at '/ts': 'yesterday' is not valid date-time: less than 20 characters long"]}`),
+		},
+	},
+	{
+		"object",
+		config.Config{
+			Settings: map[string]interface{}{
+				"schema": formatJSONSchemaTestSchema,
+				"object": map[string]interface{}{
+					"source_key": "event",
+					"target_key": "event_errors",
+				},
+			},
+		},
+		[]byte(`{"event":{"id":"1"}}`),
+		[][]byte{
+			[]byte(`{"event":{"id":"1"},"event_errors":["at '/id': got string, want integer"]}`),
+		},
+	},
+}
+
+func TestFormatJSONSchema(t *testing.T) {
+	ctx := context.TODO()
+	for _, test := range formatJSONSchemaTests {
+		t.Run(test.name, func(t *testing.T) {
+			tf, err := newFormatJSONSchema(ctx, test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg := message.New().SetData(test.test)
+			result, err := tf.Transform(ctx, msg)
+			if err != nil {
+				t.Error(err)
+			}
+
+			var data [][]byte
+			for _, c := range result {
+				data = append(data, c.Data())
+			}
+
+			if !reflect.DeepEqual(data, test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, data)
+			}
+		})
+	}
+}
+
+func benchmarkFormatJSONSchema(b *testing.B, tf *formatJSONSchema, data []byte) {
+	ctx := context.TODO()
+	for i := 0; i < b.N; i++ {
+		msg := message.New().SetData(data)
+		_, _ = tf.Transform(ctx, msg)
+	}
+}
+
+func BenchmarkFormatJSONSchema(b *testing.B) {
+	for _, test := range formatJSONSchemaTests {
+		tf, err := newFormatJSONSchema(context.TODO(), test.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(test.name,
+			func(b *testing.B) {
+				benchmarkFormatJSONSchema(b, tf, test.test)
+			},
+		)
+	}
+}
+
+func FuzzTestFormatJSONSchema(f *testing.F) {
+	testcases := [][]byte{
+		[]byte(`{"id":1}`),
+		[]byte(`{"ts":"yesterday"}`),
+		[]byte(`[1,2,3]`),
+		[]byte(`foo`),
+		[]byte(``),
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc)
+	}
+
+	tf, err := newFormatJSONSchema(context.TODO(), config.Config{
+		Settings: map[string]interface{}{
+			"schema": formatJSONSchemaTestSchema,
+			"object": map[string]interface{}{
+				"target_key": "errors",
+			},
+		},
+	})
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ctx := context.TODO()
+		msg := message.New().SetData(data)
+
+		_, err := tf.Transform(ctx, msg)
+		if err != nil {
+			return
+		}
+	})
+}
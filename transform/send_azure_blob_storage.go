@@ -0,0 +1,243 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/aggregate"
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/file"
+	"github.com/brexhq/substation/v2/internal/media"
+	"github.com/brexhq/substation/v2/internal/secrets"
+)
+
+type sendAzureBlobStorageConfig struct {
+	// FilePath determines how the name of the uploaded blob is constructed.
+	// See filePath.New for more information.
+	FilePath file.Path `json:"file_path"`
+	// UseBatchKeyAsPrefix determines if the batch key should be used as the prefix.
+	UseBatchKeyAsPrefix bool `json:"use_batch_key_as_prefix"`
+	// AuxTransforms are applied to batched data before it is sent.
+	AuxTransforms []config.Config `json:"auxiliary_transforms"`
+
+	ID     string         `json:"id"`
+	Object iconfig.Object `json:"object"`
+	Batch  iconfig.Batch  `json:"batch"`
+	Azure  iconfig.Azure  `json:"azure"`
+}
+
+func (c *sendAzureBlobStorageConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *sendAzureBlobStorageConfig) Validate() error {
+	if c.Azure.Resource == "" {
+		return fmt.Errorf("azure.resource: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	u, err := url.Parse(c.Azure.Resource)
+	if err != nil || u.Host == "" || path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+		return fmt.Errorf("azure.resource: %v", iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newSendAzureBlobStorage(ctx context.Context, cfg config.Config) (*sendAzureBlobStorage, error) {
+	conf := sendAzureBlobStorageConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("transform send_azure_blob_storage: %v", err)
+	}
+
+	if conf.ID == "" {
+		conf.ID = "send_azure_blob_storage"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	tf := sendAzureBlobStorage{
+		conf: conf,
+	}
+
+	agg, err := aggregate.New(aggregate.Config{
+		Count:    conf.Batch.Count,
+		Size:     conf.Batch.Size,
+		Duration: conf.Batch.Duration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+	tf.agg = agg
+
+	if len(conf.AuxTransforms) > 0 {
+		tf.tforms = make([]Transformer, len(conf.AuxTransforms))
+		for i, c := range conf.AuxTransforms {
+			t, err := New(context.Background(), c)
+			if err != nil {
+				return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+			}
+
+			tf.tforms[i] = t
+		}
+	}
+
+	// The container client is created from a connection string if one is
+	// provided, otherwise the default Azure credential chain is used.
+	if conf.Azure.ConnectionString != "" {
+		cs, err := secrets.Interpolate(ctx, conf.Azure.ConnectionString)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		// https://my-account.blob.core.windows.net/my-container -> my-container
+		u, _ := url.Parse(conf.Azure.Resource)
+		client, err := container.NewClientFromConnectionString(cs, path.Base(u.Path), nil)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.client = client
+		return &tf, nil
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	client, err := container.NewClient(conf.Azure.Resource, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+	tf.client = client
+
+	return &tf, nil
+}
+
+type sendAzureBlobStorage struct {
+	conf   sendAzureBlobStorageConfig
+	client *container.Client
+
+	mu     sync.Mutex
+	agg    *aggregate.Aggregate
+	tforms []Transformer
+}
+
+func (tf *sendAzureBlobStorage) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	if msg.IsControl() {
+		for key := range tf.agg.GetAll() {
+			if tf.agg.Count(key) == 0 {
+				continue
+			}
+
+			if err := tf.send(ctx, key); err != nil {
+				return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+			}
+		}
+
+		tf.agg.ResetAll()
+		return []*message.Message{msg}, nil
+	}
+
+	// If this value does not exist, then all data is batched together.
+	key := msg.GetValue(tf.conf.Object.BatchKey).String()
+	if ok := tf.agg.Add(key, msg.Data()); ok {
+		return []*message.Message{msg}, nil
+	}
+
+	if err := tf.send(ctx, key); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+	}
+
+	// If data cannot be added after reset, then the batch is misconfigured.
+	tf.agg.Reset(key)
+	if ok := tf.agg.Add(key, msg.Data()); !ok {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, errBatchNoMoreData)
+	}
+
+	return []*message.Message{msg}, nil
+}
+
+func (tf *sendAzureBlobStorage) String() string {
+	b, _ := json.Marshal(tf.conf)
+	return secrets.Redact(string(b))
+}
+
+func (tf *sendAzureBlobStorage) send(ctx context.Context, key string) error {
+	p := tf.conf.FilePath
+	if key != "" && tf.conf.UseBatchKeyAsPrefix {
+		p.Prefix = key
+	}
+
+	filePath := p.New()
+	if filePath == "" {
+		return fmt.Errorf("file path is empty")
+	}
+
+	temp, err := os.CreateTemp("", "substation")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	data, err := withTransforms(ctx, tf.tforms, tf.agg.Get(key))
+	if err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		if _, err := temp.Write(d); err != nil {
+			return err
+		}
+	}
+
+	// Flush the file before uploading to Blob Storage.
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(temp.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	mediaType, err := media.File(f)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if _, err := tf.client.NewBlockBlobClient(filePath).UploadFile(ctx, f, &blockblob.UploadFileOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobContentType: &mediaType,
+		},
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
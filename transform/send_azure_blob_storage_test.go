@@ -0,0 +1,115 @@
+package transform
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Transformer = &sendAzureBlobStorage{}
+
+// azuriteConnectionString is the well-known connection string for Azurite. This
+// can be overridden with the AZURITE_CONNECTION_STRING environment variable.
+const azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
+
+func TestSendAzureBlobStorageValidate(t *testing.T) {
+	for _, resource := range []string{
+		"",
+		"my-container",
+		"https://devstoreaccount1.blob.core.windows.net",
+		"https://devstoreaccount1.blob.core.windows.net/",
+	} {
+		if _, err := newSendAzureBlobStorage(context.TODO(), config.Config{
+			Settings: map[string]interface{}{
+				"azure": map[string]interface{}{
+					"resource":          resource,
+					"connection_string": azuriteConnectionString,
+				},
+			},
+		}); err == nil {
+			t.Errorf("expected error, got nil: %q", resource)
+		}
+	}
+}
+
+// TestSendAzureBlobStorage requires Azurite and is skipped if it is not running:
+//
+//	docker run -p 10000:10000 mcr.microsoft.com/azure-storage/azurite azurite-blob --blobHost 0.0.0.0
+func TestSendAzureBlobStorage(t *testing.T) {
+	cs := azuriteConnectionString
+	if v, ok := os.LookupEnv("AZURITE_CONNECTION_STRING"); ok {
+		cs = v
+	}
+
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:10000", time.Second)
+	if err != nil {
+		t.Skip("azurite is not running")
+	}
+	conn.Close()
+
+	ctx := context.TODO()
+	container := strings.ToLower(strings.ReplaceAll(t.Name(), "_", "-")) + "-" + time.Now().Format("150405")
+
+	client, err := azblob.NewClientFromConnectionString(cs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CreateContainer(ctx, container, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _, _ = client.DeleteContainer(ctx, container, nil) }()
+
+	tf, err := newSendAzureBlobStorage(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"file_path": map[string]interface{}{
+				"prefix": "test",
+				"suffix": ".jsonl",
+			},
+			"azure": map[string]interface{}{
+				"resource":          "https://devstoreaccount1.blob.core.windows.net/" + container,
+				"connection_string": cs,
+			},
+			"auxiliary_transforms": []config.Config{
+				{Type: "aggregate_to_array"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range []string{`{"a":"b"}`, `{"c":"d"}`} {
+		if _, err := tf.Transform(ctx, message.New().SetData([]byte(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := tf.Transform(ctx, message.New().AsControl()); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.DownloadStream(ctx, container, "test.jsonl", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `[{"a":"b"},{"c":"d"}]`; string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+}
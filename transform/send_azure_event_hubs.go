@@ -0,0 +1,252 @@
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+
+	"github.com/brexhq/substation/v2/internal/aggregate"
+	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/secrets"
+)
+
+// Events greater than 1 MB in size cannot be
+// sent to an Event Hub (Standard tier).
+const sendAzureEventHubsMessageSizeLimit = 1000 * 1000
+
+// errSendAzureEventHubsMessageSizeLimit is returned when data
+// exceeds the Event Hubs event size limit. If this error occurs,
+// then conditions or transforms should be applied to either drop
+// or reduce the size of the data.
+var errSendAzureEventHubsMessageSizeLimit = fmt.Errorf("data exceeded size limit")
+
+type sendAzureEventHubsConfig struct {
+	// UseBatchKeyAsPartitionKey determines if the batch key should be used as the partition key.
+	UseBatchKeyAsPartitionKey bool `json:"use_batch_key_as_partition_key"`
+	// AuxTransforms are applied to batched data before it is sent.
+	AuxTransforms []config.Config `json:"auxiliary_transforms"`
+
+	ID     string         `json:"id"`
+	Object iconfig.Object `json:"object"`
+	Batch  iconfig.Batch  `json:"batch"`
+	Azure  iconfig.Azure  `json:"azure"`
+}
+
+func (c *sendAzureEventHubsConfig) Decode(in interface{}) error {
+	return iconfig.Decode(in, c)
+}
+
+func (c *sendAzureEventHubsConfig) Validate() error {
+	if c.Azure.Resource == "" {
+		return fmt.Errorf("azure.resource: %v", iconfig.ErrMissingRequiredOption)
+	}
+
+	u, err := url.Parse(c.Azure.Resource)
+	if err != nil || u.Host == "" || path.Base(u.Path) == "/" || path.Base(u.Path) == "." {
+		return fmt.Errorf("azure.resource: %v", iconfig.ErrInvalidOption)
+	}
+
+	return nil
+}
+
+func newSendAzureEventHubs(ctx context.Context, cfg config.Config) (*sendAzureEventHubs, error) {
+	conf := sendAzureEventHubsConfig{}
+	if err := conf.Decode(cfg.Settings); err != nil {
+		return nil, fmt.Errorf("transform send_azure_event_hubs: %v", err)
+	}
+
+	if conf.ID == "" {
+		conf.ID = "send_azure_event_hubs"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	tf := sendAzureEventHubs{
+		conf: conf,
+	}
+
+	// Event Hubs limits batch operations to 1MB (Standard tier).
+	size := sendAzureEventHubsMessageSizeLimit
+	if conf.Batch.Size > 0 && conf.Batch.Size <= size {
+		size = conf.Batch.Size
+	}
+
+	agg, err := aggregate.New(aggregate.Config{
+		Count:    conf.Batch.Count,
+		Size:     size,
+		Duration: conf.Batch.Duration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+	tf.agg = agg
+
+	if len(conf.AuxTransforms) > 0 {
+		tf.tforms = make([]Transformer, len(conf.AuxTransforms))
+		for i, c := range conf.AuxTransforms {
+			t, err := New(context.Background(), c)
+			if err != nil {
+				return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+			}
+
+			tf.tforms[i] = t
+		}
+	}
+
+	// sb://my-namespace.servicebus.windows.net/my-hub -> my-namespace.servicebus.windows.net, my-hub
+	u, _ := url.Parse(conf.Azure.Resource)
+	namespace, hub := u.Host, path.Base(u.Path)
+
+	// The producer client is created from a connection string if one is
+	// provided, otherwise the default Azure credential chain is used.
+	if conf.Azure.ConnectionString != "" {
+		cs, err := secrets.Interpolate(ctx, conf.Azure.ConnectionString)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		client, err := azeventhubs.NewProducerClientFromConnectionString(cs, hub, nil)
+		if err != nil {
+			return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+		}
+
+		tf.client = client
+		return &tf, nil
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+
+	client, err := azeventhubs.NewProducerClient(namespace, hub, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("transform %s: %v", conf.ID, err)
+	}
+	tf.client = client
+
+	return &tf, nil
+}
+
+type sendAzureEventHubs struct {
+	conf   sendAzureEventHubsConfig
+	client *azeventhubs.ProducerClient
+
+	mu     sync.Mutex
+	agg    *aggregate.Aggregate
+	tforms []Transformer
+}
+
+func (tf *sendAzureEventHubs) Transform(ctx context.Context, msg *message.Message) ([]*message.Message, error) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	if msg.IsControl() {
+		for key := range tf.agg.GetAll() {
+			if tf.agg.Count(key) == 0 {
+				continue
+			}
+
+			if err := tf.send(ctx, key); err != nil {
+				return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+			}
+		}
+
+		tf.agg.ResetAll()
+		return []*message.Message{msg}, nil
+	}
+
+	if len(msg.Data()) > sendAzureEventHubsMessageSizeLimit {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, errSendAzureEventHubsMessageSizeLimit)
+	}
+
+	// If this value does not exist, then all data is batched together.
+	key := msg.GetValue(tf.conf.Object.BatchKey).String()
+	if ok := tf.agg.Add(key, msg.Data()); ok {
+		return []*message.Message{msg}, nil
+	}
+
+	if err := tf.send(ctx, key); err != nil {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+	}
+
+	// If data cannot be added after reset, then the batch is misconfigured.
+	tf.agg.Reset(key)
+	if ok := tf.agg.Add(key, msg.Data()); !ok {
+		return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, errBatchNoMoreData)
+	}
+
+	return []*message.Message{msg}, nil
+}
+
+func (tf *sendAzureEventHubs) String() string {
+	b, _ := json.Marshal(tf.conf)
+	return secrets.Redact(string(b))
+}
+
+func (tf *sendAzureEventHubs) send(ctx context.Context, key string) error {
+	data, err := withTransforms(ctx, tf.tforms, tf.agg.Get(key))
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	// If no partition key is used, then Event Hubs chooses the partition.
+	opts := &azeventhubs.EventDataBatchOptions{}
+	if tf.conf.UseBatchKeyAsPartitionKey && key != "" {
+		opts.PartitionKey = &key
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	batch, err := tf.client.NewEventDataBatch(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		err := batch.AddEventData(&azeventhubs.EventData{Body: d}, nil)
+		if err == nil {
+			continue
+		}
+
+		// Auxiliary transforms can increase the size of the data, so
+		// the batch is sent when it is full and a new batch is created.
+		if !errors.Is(err, azeventhubs.ErrEventDataTooLarge) || batch.NumEvents() == 0 {
+			return err
+		}
+
+		if err := tf.client.SendEventDataBatch(ctx, batch, nil); err != nil {
+			return err
+		}
+
+		batch, err = tf.client.NewEventDataBatch(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		if err := batch.AddEventData(&azeventhubs.EventData{Body: d}, nil); err != nil {
+			return err
+		}
+	}
+
+	if batch.NumEvents() == 0 {
+		return nil
+	}
+
+	return tf.client.SendEventDataBatch(ctx, batch, nil)
+}
@@ -0,0 +1,161 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azeventhubs/v2"
+
+	"github.com/brexhq/substation/v2/config"
+	"github.com/brexhq/substation/v2/message"
+)
+
+var _ Transformer = &sendAzureEventHubs{}
+
+// eventHubsEmulatorConnectionString is the well-known connection string for the
+// Event Hubs emulator. This can be overridden with the
+// AZURE_EVENTHUBS_CONNECTION_STRING environment variable.
+const eventHubsEmulatorConnectionString = "Endpoint=sb://localhost;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=SAS_KEY_VALUE;UseDevelopmentEmulator=true;"
+
+func TestSendAzureEventHubsValidate(t *testing.T) {
+	for _, resource := range []string{
+		"",
+		"my-hub",
+		"sb://my-namespace.servicebus.windows.net",
+		"sb://my-namespace.servicebus.windows.net/",
+	} {
+		if _, err := newSendAzureEventHubs(context.TODO(), config.Config{
+			Settings: map[string]interface{}{
+				"azure": map[string]interface{}{
+					"resource":          resource,
+					"connection_string": eventHubsEmulatorConnectionString,
+				},
+			},
+		}); err == nil {
+			t.Errorf("expected error, got nil: %q", resource)
+		}
+	}
+}
+
+func TestSendAzureEventHubsSizeLimit(t *testing.T) {
+	ctx := context.TODO()
+
+	tf, err := newSendAzureEventHubs(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"azure": map[string]interface{}{
+				"resource":          "sb://localhost/eh1",
+				"connection_string": eventHubsEmulatorConnectionString,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, sendAzureEventHubsMessageSizeLimit+1)
+	if _, err := tf.Transform(ctx, message.New().SetData(data)); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+// TestSendAzureEventHubs requires the Event Hubs emulator with the default
+// configuration (an event hub named "eh1") and is skipped if it is not running.
+func TestSendAzureEventHubs(t *testing.T) {
+	cs := eventHubsEmulatorConnectionString
+	if v, ok := os.LookupEnv("AZURE_EVENTHUBS_CONNECTION_STRING"); ok {
+		cs = v
+	}
+
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:5672", time.Second)
+	if err != nil {
+		t.Skip("event hubs emulator is not running")
+	}
+	conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	consumer, err := azeventhubs.NewConsumerClientFromConnectionString(cs, "eh1", azeventhubs.DefaultConsumerGroup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close(ctx)
+
+	props, err := consumer.GetEventHubProperties(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only events sent after the test starts are received.
+	latest := true
+	partitions := make([]*azeventhubs.PartitionClient, 0, len(props.PartitionIDs))
+	for _, id := range props.PartitionIDs {
+		pc, err := consumer.NewPartitionClient(id, &azeventhubs.PartitionClientOptions{
+			StartPosition: azeventhubs.StartPosition{Latest: &latest},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close(ctx)
+
+		partitions = append(partitions, pc)
+	}
+
+	tf, err := newSendAzureEventHubs(ctx, config.Config{
+		Settings: map[string]interface{}{
+			"azure": map[string]interface{}{
+				"resource":          "sb://localhost/eh1",
+				"connection_string": cs,
+			},
+			"object": map[string]interface{}{
+				"batch_key": "a",
+			},
+			"use_batch_key_as_partition_key": true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`}
+	for _, data := range expected {
+		if _, err := tf.Transform(ctx, message.New().SetData([]byte(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := tf.Transform(ctx, message.New().AsControl()); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	for _, pc := range partitions {
+		rctx, rcancel := context.WithTimeout(ctx, 5*time.Second)
+		events, err := pc.ReceiveEvents(rctx, len(expected), nil)
+		rcancel()
+
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatal(err)
+		}
+
+		for _, e := range events {
+			received = append(received, string(e.Body))
+		}
+	}
+
+	sort.Strings(received)
+	if len(received) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, received)
+	}
+
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, received)
+		}
+	}
+}
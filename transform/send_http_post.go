@@ -143,7 +143,7 @@ func (tf *sendHTTPPost) Transform(ctx context.Context, msg *message.Message) ([]
 
 func (tf *sendHTTPPost) String() string {
 	b, _ := json.Marshal(tf.conf)
-	return string(b)
+	return secrets.Redact(string(b))
 }
 
 func (tf *sendHTTPPost) send(ctx context.Context, key string) error {
@@ -10,6 +10,7 @@ import (
 	"github.com/brexhq/substation/v2/message"
 
 	iconfig "github.com/brexhq/substation/v2/internal/config"
+	"github.com/brexhq/substation/v2/internal/secrets"
 )
 
 var errMsgInvalidObject = fmt.Errorf("message must be JSON object")
@@ -67,6 +68,8 @@ func New(ctx context.Context, cfg config.Config) (Transformer, error) { //nolint
 		return newEnrichKVStoreItemSet(ctx, cfg)
 	case "enrich_kv_store_set_add":
 		return newEnrichKVStoreSetAdd(ctx, cfg)
+	case "enrich_sigma":
+		return newEnrichSigma(ctx, cfg)
 	// Format transforms.
 	case "format_from_base64":
 		return newFormatFromBase64(ctx, cfg)
@@ -82,6 +85,8 @@ func New(ctx context.Context, cfg config.Config) (Transformer, error) { //nolint
 		return newFormatFromPrettyPrint(ctx, cfg)
 	case "format_from_zip":
 		return newFormatFromZip(ctx, cfg)
+	case "format_json_schema":
+		return newFormatJSONSchema(ctx, cfg)
 	// Hash transforms.
 	case "hash_md5":
 		return newHashMD5(ctx, cfg)
@@ -156,6 +161,10 @@ func New(ctx context.Context, cfg config.Config) (Transformer, error) { //nolint
 		return newSendAWSSNS(ctx, cfg)
 	case "send_aws_sqs":
 		return newSendAWSSQS(ctx, cfg)
+	case "send_azure_blob_storage":
+		return newSendAzureBlobStorage(ctx, cfg)
+	case "send_azure_event_hubs":
+		return newSendAzureEventHubs(ctx, cfg)
 	case "send_gcp_storage":
 		return newSendGCPStorage(ctx, cfg)
 	case "send_file":
@@ -232,8 +241,9 @@ func Apply(ctx context.Context, tf []Transformer, msgs ...*message.Message) ([]*
 			rMsgs, err := tf[i].Transform(ctx, m)
 			if err != nil {
 				// We immediately return if a transform hits an unrecoverable
-				// error on a message.
-				return nil, err
+				// error on a message. Errors can contain interpolated secrets
+				// (e.g., URLs), so secrets are removed from the error.
+				return nil, secrets.RedactError(err)
 			}
 			nextResultMsgs = append(nextResultMsgs, rMsgs...)
 		}
@@ -64,6 +64,10 @@ func (tf *utilityMetricsCount) Transform(ctx context.Context, msg *message.Messa
 			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
 		}
 
+		if err := metrics.Flush(ctx, tf.metric); err != nil {
+			return nil, fmt.Errorf("transform %s: %v", tf.conf.ID, err)
+		}
+
 		atomic.StoreUint32(&tf.count, 0)
 		return []*message.Message{msg}, nil
 	}